@@ -0,0 +1,180 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+// devicePluginPodLabelSelector matches the pods DevicePluginResetReconciler bounces, the same
+// selector the config-daemon's removed restartDevicePluginPod() used to list against.
+var devicePluginPodLabelSelector = client.MatchingLabels{"app": "sriov-device-plugin"}
+
+// devicePluginResetRequeueDelay paces the poll between "deleted the stale pods" and "the
+// DaemonSet's replacements are Ready" - rechecking on every reconcile without it would busy-loop.
+const devicePluginResetRequeueDelay = 5 * time.Second
+
+// DevicePluginResetReconciler watches SriovNetworkNodeState for
+// constants.DevicePluginResetAnnotation changes (written by the config-daemon after a successful
+// apply, see pkg/daemon.Daemon.annotateDevicePluginReset) and bounces that node's
+// app=sriov-device-plugin pod(s) on its behalf. This used to be the config-daemon's job, which
+// meant every node needed pods list/delete RBAC in the operator namespace; centralizing it here
+// means only the operator needs that privilege.
+type DevicePluginResetReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	recorder record.EventRecorder
+}
+
+func NewDevicePluginResetReconciler(
+	client client.Client,
+	scheme *runtime.Scheme,
+	recorder record.EventRecorder,
+) *DevicePluginResetReconciler {
+	return &DevicePluginResetReconciler{
+		Client:   client,
+		Scheme:   scheme,
+		recorder: recorder,
+	}
+}
+
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;delete
+
+func (r *DevicePluginResetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&sriovnetworkv1.SriovNetworkNodeState{}).
+		WithEventFilter(DevicePluginResetAnnotationPredicate{}).
+		Complete(r)
+}
+
+// Reconcile cordons req's node, deletes every app=sriov-device-plugin pod running on it, and
+// uncordons once the DaemonSet's replacements are all Ready again. It's re-entrant: a node found
+// already cordoned is assumed to be mid-reset from an earlier pass, so it's left cordoned while
+// the replacement pods finish coming up rather than being deleted again.
+func (r *DevicePluginResetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("node", req.Name)
+
+	nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+	if err := r.Get(ctx, req.NamespacedName, nodeState); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	node := &corev1.Node{}
+	if err := r.Get(ctx, types.NamespacedName{Name: nodeState.GetName()}, node); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	pods, err := r.devicePluginPodsOn(ctx, node.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !node.Spec.Unschedulable {
+		logger.Info("cordoning node for device-plugin reset", "generation", nodeState.Annotations[constants.DevicePluginResetAnnotation])
+		if err := r.setUnschedulable(ctx, node, true); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.deletePods(ctx, pods); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: devicePluginResetRequeueDelay}, nil
+	}
+
+	if !allPodsReady(pods) {
+		logger.V(1).Info("waiting for device-plugin pod(s) to come back up", "podCount", len(pods))
+		return ctrl.Result{RequeueAfter: devicePluginResetRequeueDelay}, nil
+	}
+
+	logger.Info("device-plugin pod(s) ready, uncordoning node")
+	if err := r.setUnschedulable(ctx, node, false); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// devicePluginPodsOn returns every app=sriov-device-plugin pod currently scheduled to nodeName,
+// not just the first one found: a rolling change to the device-plugin DaemonSet can leave two
+// instances (one Terminating, one ContainerCreating) on the same node at once.
+func (r *DevicePluginResetReconciler) devicePluginPodsOn(ctx context.Context, nodeName string) ([]corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(namespace), devicePluginPodLabelSelector); err != nil {
+		return nil, err
+	}
+
+	var onNode []corev1.Pod
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName == nodeName {
+			onNode = append(onNode, pod)
+		}
+	}
+	return onNode, nil
+}
+
+func (r *DevicePluginResetReconciler) deletePods(ctx context.Context, pods []corev1.Pod) error {
+	for i := range pods {
+		if pods[i].DeletionTimestamp != nil {
+			continue
+		}
+		if err := r.Delete(ctx, &pods[i]); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *DevicePluginResetReconciler) setUnschedulable(ctx context.Context, node *corev1.Node, unschedulable bool) error {
+	patch := client.MergeFrom(node.DeepCopy())
+	node.Spec.Unschedulable = unschedulable
+	return r.Patch(ctx, node, patch)
+}
+
+// allPodsReady reports whether pods is non-empty and every pod in it is not terminating and has
+// its Ready condition set to true.
+func allPodsReady(pods []corev1.Pod) bool {
+	if len(pods) == 0 {
+		return false
+	}
+	for i := range pods {
+		if pods[i].DeletionTimestamp != nil || !isPodReady(&pods[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -23,6 +23,12 @@ import (
 	"os"
 	"strings"
 
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -80,6 +86,37 @@ func (DrainAnnotationPredicate) Update(e event.UpdateEvent) bool {
 	return oldAnno != newAnno
 }
 
+// DevicePluginResetAnnotationPredicate triggers the device-plugin-reset controller only when
+// constants.DevicePluginResetAnnotation changes on a SriovNetworkNodeState. The config-daemon
+// writes that annotation (set to the state's generation) instead of listing and deleting the
+// device-plugin pod itself, which used to require pod list/delete RBAC on every node; the
+// controller watching this predicate cordons the node, deletes the device-plugin pod, and
+// uncordons once the plugin reports Ready again.
+type DevicePluginResetAnnotationPredicate struct {
+	predicate.Funcs
+}
+
+// Create filters out create events (including the initial list when the operator starts), so a
+// node state is only reconciled once its annotation actually changes.
+func (DevicePluginResetAnnotationPredicate) Create(event.CreateEvent) bool {
+	return false
+}
+
+func (DevicePluginResetAnnotationPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+
+	oldAnno, hasOldAnno := e.ObjectOld.GetAnnotations()[constants.DevicePluginResetAnnotation]
+	newAnno := e.ObjectNew.GetAnnotations()[constants.DevicePluginResetAnnotation]
+
+	if newAnno == "" {
+		return false
+	}
+
+	return !hasOldAnno || oldAnno != newAnno
+}
+
 func GetImagePullSecrets() []string {
 	imagePullSecrets := os.Getenv("IMAGE_PULL_SECRETS")
 	if imagePullSecrets != "" {
@@ -89,6 +126,47 @@ func GetImagePullSecrets() []string {
 	}
 }
 
+// DeleteGeneratedWebhooksAndRBAC best-effort deletes the MutatingWebhookConfiguration/
+// ValidatingWebhookConfiguration objects named in the webhooks map, plus the cluster-scoped
+// ClusterRole/ClusterRoleBinding pairs named in clusterRoleNames, so a SriovOperatorConfig
+// finalizer can clean these up on deletion. Every deletion is attempted even if an earlier one
+// failed, and a missing object is not an error; the returned error aggregates whatever genuinely
+// failed, so the finalizer can decide whether it's safe to let the CR finish deleting.
+func DeleteGeneratedWebhooksAndRBAC(ctx context.Context, c client.Client, clusterRoleNames []string) error {
+	logger := log.FromContext(ctx)
+	var errs []error
+
+	for name := range webhooks {
+		mwc := &admissionregistrationv1.MutatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if err := c.Delete(ctx, mwc); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to delete MutatingWebhookConfiguration", "name", name)
+			errs = append(errs, err)
+		}
+
+		vwc := &admissionregistrationv1.ValidatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if err := c.Delete(ctx, vwc); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to delete ValidatingWebhookConfiguration", "name", name)
+			errs = append(errs, err)
+		}
+	}
+
+	for _, name := range clusterRoleNames {
+		cr := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if err := c.Delete(ctx, cr); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to delete ClusterRole", "name", name)
+			errs = append(errs, err)
+		}
+
+		crb := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if err := c.Delete(ctx, crb); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to delete ClusterRoleBinding", "name", name)
+			errs = append(errs, err)
+		}
+	}
+
+	return kerrors.NewAggregate(errs)
+}
+
 func formatJSON(str string) (string, error) {
 	var prettyJSON bytes.Buffer
 	if err := json.Indent(&prettyJSON, []byte(str), "", "    "); err != nil {
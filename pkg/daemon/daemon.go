@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"fmt"
@@ -14,7 +15,6 @@ import (
 	"time"
 
 	"golang.org/x/time/rate"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -27,6 +27,7 @@ import (
 	snclientset "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/clientset/versioned"
 	sninformer "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/client/informers/externalversions"
 	consts "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/featuregates"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
 	snolog "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/log"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
@@ -86,6 +87,11 @@ type Daemon struct {
 
 	disableDrain bool
 
+	// rebootShutdownTimeout bounds how long rebootNode() waits for a graceful-shutdown-capable
+	// kubelet to evict/terminate pods before the node goes down. Hot-reloaded from
+	// SriovOperatorConfig.Spec.RebootShutdownTimeoutSeconds.
+	rebootShutdownTimeout time.Duration
+
 	workqueue workqueue.RateLimitingInterface
 
 	storeManager utils.StoreManagerInterface
@@ -100,6 +106,19 @@ const (
 	syncStatusSucceeded  = "Succeeded"
 	syncStatusFailed     = "Failed"
 	syncStatusInProgress = "InProgress"
+	// syncStatusKernelArgsPending means the config-daemon applied everything it could, but some
+	// kernel argument it expects to be present (e.g. intel_iommu=on) is still missing from
+	// /proc/cmdline, so the node needs another reboot before it can be trusted as Succeeded
+	syncStatusKernelArgsPending = "KernelArgsPending"
+
+	// defaultRebootShutdownTimeout is used until SriovOperatorConfig.Spec.RebootShutdownTimeoutSeconds
+	// is set to something else.
+	defaultRebootShutdownTimeout = 90 * time.Second
+
+	// shutdownDelayFile is created before rebootNode() triggers the reboot and removed once the
+	// daemon's own preStop drain has finished, so other SR-IOV consumers' preStop hooks can poll
+	// for its absence before letting kubelet finish terminating their pod.
+	shutdownDelayFile = "/tmp/sriov-delay-shutdown"
 )
 
 var namespace = os.Getenv("NAMESPACE")
@@ -119,20 +138,22 @@ func New(
 	devMode bool,
 ) *Daemon {
 	return &Daemon{
-		name:              nodeName,
-		platform:          platformType,
-		useSystemdService: useSystemdService,
-		devMode:           devMode,
-		client:            client,
-		kubeClient:        kubeClient,
-		openshiftContext:  openshiftContext,
-		serviceManager:    service.NewServiceManager("/host"),
-		exitCh:            exitCh,
-		stopCh:            stopCh,
-		syncCh:            syncCh,
-		refreshCh:         refreshCh,
-		desiredNodeState:  &sriovnetworkv1.SriovNetworkNodeState{},
-		currentNodeState:  &sriovnetworkv1.SriovNetworkNodeState{},
+		name:                  nodeName,
+		platform:              platformType,
+		useSystemdService:     useSystemdService,
+		devMode:               devMode,
+		client:                client,
+		kubeClient:            kubeClient,
+		openshiftContext:      openshiftContext,
+		serviceManager:        service.NewServiceManager("/host"),
+		exitCh:                exitCh,
+		stopCh:                stopCh,
+		syncCh:                syncCh,
+		refreshCh:             refreshCh,
+		desiredNodeState:      &sriovnetworkv1.SriovNetworkNodeState{},
+		currentNodeState:      &sriovnetworkv1.SriovNetworkNodeState{},
+		mu:                    &sync.Mutex{},
+		rebootShutdownTimeout: defaultRebootShutdownTimeout,
 		workqueue: workqueue.NewNamedRateLimitingQueue(workqueue.NewMaxOfRateLimiter(
 			&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(updateDelay), 1)},
 			workqueue.NewItemExponentialFailureRateLimiter(1*time.Second, maxUpdateBackoff)), "SriovNetworkNodeState"),
@@ -160,7 +181,11 @@ func (dn *Daemon) Run(stopCh <-chan struct{}, exitCh <-chan error) error {
 	hostManager := host.NewHostManager(dn.useSystemdService)
 	dn.hostManager = hostManager
 	if !dn.useSystemdService {
-		dn.hostManager.TryEnableRdma()
+		if rdmaEnabled, err := dn.hostManager.CheckRDMAEnabled(); err != nil {
+			log.Log.Error(err, "failed to check if RDMA is enabled")
+		} else if !rdmaEnabled {
+			log.Log.Info("RDMA kernel modules are not loaded; install rdma-core via a MachineConfig or base image")
+		}
 		dn.hostManager.TryEnableTun()
 		dn.hostManager.TryEnableVhostNet()
 		err := systemd.CleanSriovFilesFromHost(utils.ClusterType == utils.ClusterTypeOpenshift)
@@ -175,6 +200,10 @@ func (dn *Daemon) Run(stopCh <-chan struct{}, exitCh <-chan error) error {
 	}
 	dn.storeManager = storeManager
 
+	if err := dn.captureInitialState(); err != nil {
+		log.Log.Error(err, "failed to capture the node's pre-operator interface state")
+	}
+
 	if err := dn.prepareNMUdevRule(); err != nil {
 		log.Log.Error(err, "failed to prepare udev files to disable network manager on requested VFs")
 	}
@@ -183,7 +212,6 @@ func (dn *Daemon) Run(stopCh <-chan struct{}, exitCh <-chan error) error {
 	}
 
 	var timeout int64 = 5
-	dn.mu = &sync.Mutex{}
 	informerFactory := sninformer.NewFilteredSharedInformerFactory(dn.client,
 		time.Second*15,
 		namespace,
@@ -324,11 +352,92 @@ func (dn *Daemon) operatorConfigChangeHandler(old, new interface{}) {
 	newCfg := new.(*sriovnetworkv1.SriovOperatorConfig)
 	snolog.SetLogLevel(newCfg.Spec.LogLevel)
 
+	// Hot-reload the feature gate set on every SriovOperatorConfig change, so operators can flip
+	// experimental features without restarting the daemon.
+	featuregates.Replace(newCfg.Spec.FeatureGates)
+
 	newDisableDrain := newCfg.Spec.DisableDrain
 	if dn.disableDrain != newDisableDrain {
 		dn.disableDrain = newDisableDrain
 		log.Log.Info("Set Disable Drain", "value", dn.disableDrain)
 	}
+
+	newRebootShutdownTimeout := defaultRebootShutdownTimeout
+	if newCfg.Spec.RebootShutdownTimeoutSeconds > 0 {
+		newRebootShutdownTimeout = time.Duration(newCfg.Spec.RebootShutdownTimeoutSeconds) * time.Second
+	}
+	if dn.rebootShutdownTimeout != newRebootShutdownTimeout {
+		dn.rebootShutdownTimeout = newRebootShutdownTimeout
+		log.Log.Info("Set reboot shutdown timeout", "value", dn.rebootShutdownTimeout)
+	}
+}
+
+// readSystemdPhaseResult checks whether the named systemd phase service (sriov-config or
+// sriov-config-post-network) is enabled on the host and, if so, reads back whatever SriovResult it
+// wrote; if the service was never installed, it synthesizes a failure instead of trying to read a
+// result file that will never exist.
+func (dn *Daemon) readSystemdPhaseResult(servicePath, serviceDescription string, readResult func() (*systemd.SriovResult, error)) (*systemd.SriovResult, error) {
+	serviceEnabled, err := dn.serviceManager.IsServiceEnabled(servicePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if %s service exist on host: %w", serviceDescription, err)
+	}
+
+	// if the service doesn't exist we should continue to let the k8s plugin to create the service files
+	// this is only for k8s base environments, for openshift the sriov-operator creates a machine config to will apply
+	// the system service and reboot the node the config-daemon doesn't need to do anything.
+	if !serviceEnabled {
+		return &systemd.SriovResult{SyncStatus: syncStatusFailed,
+			LastSyncError: fmt.Sprintf("%s systemd service is not available on node", serviceDescription)}, nil
+	}
+	return readResult()
+}
+
+// captureInitialState snapshots the host's PF/VF state before the operator has touched anything,
+// by running the same discovery used to populate node status. It's stashed in
+// sriovnetworkv1.InitialState so later reconciles (e.g. ResetSriovDevice restoring a PF's
+// pre-operator MTU) and the drift check below can tell a PF the operator never took over from one
+// it's supposed to be managing.
+func (dn *Daemon) captureInitialState() error {
+	ifaces, err := dn.hostManager.DiscoverSriovDevices(dn.storeManager)
+	if err != nil {
+		return err
+	}
+	sriovnetworkv1.InitialState = sriovnetworkv1.SriovNetworkNodeState{
+		Status: sriovnetworkv1.SriovNetworkNodeStateStatus{Interfaces: ifaces},
+	}
+	return nil
+}
+
+// managedInterfaceDrifted re-discovers the host's live interface state and compares it against
+// every interface the current policy spec manages, using the same sriovnetworkv1.NeedToUpdateSriov
+// and HostManager.HasOutOfBandDrift checks the apply path already trusts. It only walks
+// Spec.Interfaces, so a PF sriovnetworkv1.InitialState shows as present before the operator ever
+// ran, but that the spec doesn't mention, is never flagged: unmanaged PFs are excluded by
+// construction rather than needing their own skip-list.
+func (dn *Daemon) managedInterfaceDrifted() bool {
+	observed, err := dn.hostManager.DiscoverSriovDevices(dn.storeManager)
+	if err != nil {
+		log.Log.Error(err, "managedInterfaceDrifted(): failed to discover current interface state")
+		return false
+	}
+
+	for _, iface := range dn.desiredNodeState.Spec.Interfaces {
+		if iface.ExternallyManaged {
+			continue
+		}
+		for _, ifaceStatus := range observed {
+			if iface.PciAddress != ifaceStatus.PciAddress {
+				continue
+			}
+			if sriovnetworkv1.NeedToUpdateSriov(&iface, &ifaceStatus) || dn.hostManager.HasOutOfBandDrift(&iface, &ifaceStatus) {
+				log.Log.Info("managedInterfaceDrifted(): managed interface diverged from spec out-of-band",
+					"address", iface.PciAddress)
+				return true
+			}
+			break
+		}
+	}
+	return false
 }
 
 func (dn *Daemon) nodeStateSyncHandler() error {
@@ -343,27 +452,29 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 	latest := dn.desiredNodeState.GetGeneration()
 	log.Log.V(0).Info("nodeStateSyncHandler(): new generation", "generation", latest)
 
-	if dn.currentNodeState.GetGeneration() == latest && !utils.ObjectHasAnnotation(dn.desiredNodeState, consts.NodeStateDrainAnnotationCurrent, consts.DrainComplete) {
+	generationUnchanged := dn.currentNodeState.GetGeneration() == latest && !utils.ObjectHasAnnotation(dn.desiredNodeState, consts.NodeStateDrainAnnotationCurrent, consts.DrainComplete)
+	if generationUnchanged && dn.managedInterfaceDrifted() {
+		log.Log.Info("nodeStateSyncHandler(): managed interface drifted from spec with no generation change, forcing re-sync")
+	} else if generationUnchanged {
 		if dn.useSystemdService {
-			serviceEnabled, err := dn.serviceManager.IsServiceEnabled(systemd.SriovServicePath)
+			sriovResult, err = dn.readSystemdPhaseResult(systemd.SriovServicePath, "sriov-config", systemd.ReadSriovResult)
 			if err != nil {
-				log.Log.Error(err, "nodeStateSyncHandler(): failed to check if sriov-config service exist on host")
+				log.Log.Error(err, "nodeStateSyncHandler(): failed to check pre-network systemd phase")
 				return err
 			}
 
-			// if the service doesn't exist we should continue to let the k8s plugin to create the service files
-			// this is only for k8s base environments, for openshift the sriov-operator creates a machine config to will apply
-			// the system service and reboot the node the config-daemon doesn't need to do anything.
-			if !serviceEnabled {
-				sriovResult = &systemd.SriovResult{SyncStatus: syncStatusFailed,
-					LastSyncError: "sriov-config systemd service is not available on node"}
-			} else {
-				sriovResult, err = systemd.ReadSriovResult()
-				if err != nil {
-					log.Log.Error(err, "nodeStateSyncHandler(): failed to load sriov result file from host")
-					return err
-				}
+			// The post-network phase (VF/representor MTU, VDPA device creation) only runs once
+			// network.target is reached, so it can fail independently of the pre-network phase;
+			// check it too so LastSyncError always names the phase that actually failed.
+			postNetworkResult, err := dn.readSystemdPhaseResult(systemd.SriovPostNetworkServicePath, "sriov-config-post-network", systemd.ReadSriovPostNetworkResult)
+			if err != nil {
+				log.Log.Error(err, "nodeStateSyncHandler(): failed to check post-network systemd phase")
+				return err
+			}
+			if postNetworkResult.LastSyncError != "" || postNetworkResult.SyncStatus == syncStatusFailed {
+				sriovResult = postNetworkResult
 			}
+
 			if sriovResult.LastSyncError != "" || sriovResult.SyncStatus == syncStatusFailed {
 				log.Log.Info("nodeStateSyncHandler(): sync failed systemd service error", "last-sync-error", sriovResult.LastSyncError)
 
@@ -476,8 +587,25 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 				return err
 			}
 		}
-		reqDrain = reqDrain || systemdConfModified
-		reqReboot = reqReboot || systemdConfModified
+
+		// The post-network phase gets its own config/result file pair: it's applied by a separate
+		// service (sriov-config-post-network.service) that only runs once network.target is
+		// reached, so it must not be able to pick up a stale result left over from a previous spec.
+		postNetworkConfModified, err := systemd.WritePostNetworkConfFile(dn.desiredNodeState, dn.devMode, dn.platform)
+		if err != nil {
+			log.Log.Error(err, "nodeStateSyncHandler(): failed to write post-network configuration file for systemd mode")
+			return err
+		}
+		if postNetworkConfModified {
+			err = systemd.RemoveSriovPostNetworkResult()
+			if err != nil {
+				log.Log.Error(err, "nodeStateSyncHandler(): failed to remove post-network result file for systemd mode")
+				return err
+			}
+		}
+
+		reqDrain = reqDrain || systemdConfModified || postNetworkConfModified
+		reqReboot = reqReboot || systemdConfModified || postNetworkConfModified
 		log.Log.V(0).Info("nodeStateSyncHandler(): systemd mode WriteConfFile results",
 			"drain-required", reqDrain, "reboot-required", reqReboot, "disable-drain", dn.disableDrain)
 
@@ -504,7 +632,14 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 	if reqDrain ||
 		(utils.ObjectHasAnnotationKey(dn.desiredNodeState, consts.NodeStateDrainAnnotationCurrent) &&
 			!utils.ObjectHasAnnotation(dn.desiredNodeState, consts.NodeStateDrainAnnotationCurrent, consts.DrainIdle)) {
-		if utils.ObjectHasAnnotation(dn.desiredNodeState, consts.NodeStateDrainAnnotationCurrent, consts.DrainComplete) {
+		if dn.openshiftContext.IsSingleNode() || dn.openshiftContext.IsMicroShift() {
+			// There is no peer node to move workloads to, so draining can only deadlock the
+			// only node in the cluster (the config-daemon would evict itself). Skip it, but make
+			// sure the outage is visible to the cluster admin.
+			log.Log.Info("nodeStateSyncHandler(): single node or MicroShift cluster detected, skipping drain",
+				"openshiftFlavor", dn.openshiftContext.OpenshiftFlavor)
+			dn.eventRecorder.SendEvent("DrainSkipped", "Drain skipped: no peer nodes available on this cluster flavor")
+		} else if utils.ObjectHasAnnotation(dn.desiredNodeState, consts.NodeStateDrainAnnotationCurrent, consts.DrainComplete) {
 			log.Log.Info("nodeStateSyncHandler(): the node complete the draining")
 		} else if !dn.isNodeDraining() {
 			if !dn.disableDrain {
@@ -547,14 +682,33 @@ func (dn *Daemon) nodeStateSyncHandler() error {
 	if reqReboot {
 		log.Log.Info("nodeStateSyncHandler(): reboot node")
 		dn.eventRecorder.SendEvent("RebootNode", "Reboot node has been initiated")
-		rebootNode()
+		dn.rebootNode()
 		return nil
 	}
 
-	// restart device plugin pod
-	log.Log.Info("nodeStateSyncHandler(): restart device plugin pod")
-	if err := dn.restartDevicePluginPod(); err != nil {
-		log.Log.Error(err, "nodeStateSyncHandler(): fail to restart device plugin pod")
+	// No plugin thinks a reboot is needed, but a previous reboot may still not have actually
+	// applied a kernel arg it was supposed to (e.g. a MachineConfig update that silently didn't
+	// take). Catch that here instead of reporting Succeeded with a node that can't bring up IOMMU.
+	missingKernelArgs, err := dn.hostManager.VerifyKernelArgs()
+	if err != nil {
+		log.Log.Error(err, "nodeStateSyncHandler(): failed to verify kernel arguments")
+		return err
+	}
+	if len(missingKernelArgs) > 0 {
+		log.Log.Info("nodeStateSyncHandler(): kernel arguments still missing after reboot, waiting for them to take effect",
+			"missing", missingKernelArgs)
+		dn.refreshCh <- Message{
+			syncStatus:    syncStatusKernelArgsPending,
+			lastSyncError: fmt.Sprintf("kernel arguments not applied: %s", strings.Join(missingKernelArgs, ", ")),
+		}
+		<-dn.syncCh
+		return nil
+	}
+
+	// request a device plugin restart
+	log.Log.Info("nodeStateSyncHandler(): request device plugin restart")
+	if err := dn.annotateDevicePluginReset(); err != nil {
+		log.Log.Error(err, "nodeStateSyncHandler(): fail to annotate node state for device plugin restart")
 		return err
 	}
 
@@ -602,60 +756,33 @@ func (dn *Daemon) applyDrainRequired() error {
 	return nil
 }
 
-func (dn *Daemon) restartDevicePluginPod() error {
-	dn.mu.Lock()
-	defer dn.mu.Unlock()
-	log.Log.V(2).Info("restartDevicePluginPod(): try to restart device plugin pod")
-
-	var podToDelete string
-	pods, err := dn.kubeClient.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{
-		LabelSelector: "app=sriov-device-plugin",
-		FieldSelector: "spec.nodeName=" + dn.name,
-	})
-	if err != nil {
-		if errors.IsNotFound(err) {
-			log.Log.Info("restartDevicePluginPod(): device plugin pod exited")
-			return nil
-		}
-		log.Log.Error(err, "restartDevicePluginPod(): Failed to list device plugin pod, retrying")
-		return err
-	}
-
-	if len(pods.Items) == 0 {
-		log.Log.Info("restartDevicePluginPod(): device plugin pod exited")
+// annotateDevicePluginReset asks the operator to bounce this node's device-plugin pod(s) by
+// writing consts.DevicePluginResetAnnotation on the node's own SriovNetworkNodeState, instead of
+// the daemon listing and deleting the pod itself. That used to require pod list/delete RBAC in
+// the operator namespace on every node; now controllers.DevicePluginResetReconciler, which watches
+// this annotation, owns cordoning the node, iterating over every pod matching
+// app=sriov-device-plugin on it (not just the first one found, so a rolling device-plugin
+// DaemonSet update can't leave a stale Terminating instance behind), and uncordoning once their
+// replacements are all Ready.
+func (dn *Daemon) annotateDevicePluginReset() error {
+	generation := strconv.FormatInt(dn.desiredNodeState.GetGeneration(), 10)
+	if dn.desiredNodeState.Annotations[consts.DevicePluginResetAnnotation] == generation {
+		log.Log.V(2).Info("annotateDevicePluginReset(): already annotated for this generation", "generation", generation)
 		return nil
 	}
-	podToDelete = pods.Items[0].Name
 
-	log.Log.V(2).Info("restartDevicePluginPod(): Found device plugin pod, deleting it", "pod-name", podToDelete)
-	err = dn.kubeClient.CoreV1().Pods(namespace).Delete(context.Background(), podToDelete, metav1.DeleteOptions{})
-	if errors.IsNotFound(err) {
-		log.Log.Info("restartDevicePluginPod(): pod to delete not found")
-		return nil
-	}
-	if err != nil {
-		log.Log.Error(err, "restartDevicePluginPod(): Failed to delete device plugin pod, retrying")
-		return err
+	newState := dn.desiredNodeState.DeepCopy()
+	if newState.Annotations == nil {
+		newState.Annotations = map[string]string{}
 	}
+	newState.Annotations[consts.DevicePluginResetAnnotation] = generation
 
-	if err := wait.PollImmediateUntil(3*time.Second, func() (bool, error) {
-		_, err := dn.kubeClient.CoreV1().Pods(namespace).Get(context.Background(), podToDelete, metav1.GetOptions{})
-		if errors.IsNotFound(err) {
-			log.Log.Info("restartDevicePluginPod(): device plugin pod exited")
-			return true, nil
-		}
-
-		if err != nil {
-			log.Log.Error(err, "restartDevicePluginPod(): Failed to check for device plugin exit, retrying")
-		} else {
-			log.Log.Info("restartDevicePluginPod(): waiting for device plugin pod to exit", "pod-name", podToDelete)
-		}
-		return false, nil
-	}, dn.stopCh); err != nil {
-		log.Log.Error(err, "restartDevicePluginPod(): failed to wait for checking pod deletion")
+	updatedState, err := dn.client.SriovnetworkV1().SriovNetworkNodeStates(namespace).Update(context.Background(), newState, metav1.UpdateOptions{})
+	if err != nil {
+		log.Log.Error(err, "annotateDevicePluginReset(): failed to annotate node state")
 		return err
 	}
-
+	dn.desiredNodeState = updatedState
 	return nil
 }
 
@@ -743,24 +870,134 @@ func (dn *Daemon) prepareNMUdevRule() error {
 	return utils.PrepareNMUdevRule(supportedVfIds)
 }
 
-func rebootNode() {
+// hostSupportsGracefulNodeShutdown reports whether the host's kubelet has a non-zero
+// shutdownGracePeriod configured, meaning its NodeShutdownManager will evict/terminate pods in
+// priority order on its own once it observes the inhibitor lock being released - so the daemon
+// doesn't need to stop kubelet itself before rebooting.
+func hostSupportsGracefulNodeShutdown() bool {
+	f, err := os.Open(path.Join(utils.FilesystemRoot, "/host/etc/kubernetes/kubelet.conf"))
+	if err != nil {
+		log.Log.V(2).Info("hostSupportsGracefulNodeShutdown(): could not read kubelet.conf, assuming no graceful shutdown support", "error", err)
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "shutdownGracePeriod:") {
+			continue
+		}
+		value := strings.TrimSpace(strings.TrimPrefix(line, "shutdownGracePeriod:"))
+		return value != "0s" && value != "0"
+	}
+	return false
+}
+
+// rebootNode triggers a node reboot. When the kubelet advertises graceful node shutdown support,
+// it lets systemd-logind's inhibitor-lock path drive the reboot so kubelet's NodeShutdownManager
+// gets a chance to evict/terminate pods in priority order; otherwise it falls back to the old
+// stop-kubelet-then-reboot behavior. Either way a sentinel file is dropped first so co-operating
+// preStop hooks of other SR-IOV consumers can delay their own termination until it's gone.
+func (dn *Daemon) rebootNode() {
 	log.Log.Info("rebootNode(): trigger node reboot")
+
+	if err := os.WriteFile(shutdownDelayFile, []byte{}, 0644); err != nil {
+		log.Log.Error(err, "rebootNode(): failed to create shutdown delay sentinel file")
+	}
+
 	exit, err := utils.Chroot("/host")
 	if err != nil {
 		log.Log.Error(err, "rebootNode(): chroot command failed")
 	}
 	defer exit()
-	// creates a new transient systemd unit to reboot the system.
-	// We explictily try to stop kubelet.service first, before anything else; this
-	// way we ensure the rest of system stays running, because kubelet may need
-	// to do "graceful" shutdown by e.g. de-registering with a load balancer.
-	// However note we use `;` instead of `&&` so we keep rebooting even
-	// if kubelet failed to shutdown - that way the machine will still eventually reboot
-	// as systemd will time out the stop invocation.
-	cmd := exec.Command("systemd-run", "--unit", "sriov-network-config-daemon-reboot",
-		"--description", "sriov-network-config-daemon reboot node", "/bin/sh", "-c", "systemctl stop kubelet.service; reboot")
+
+	var cmd *exec.Cmd
+	if hostSupportsGracefulNodeShutdown() {
+		log.Log.Info("rebootNode(): kubelet supports graceful node shutdown, rebooting via systemd-logind",
+			"timeout", dn.rebootShutdownTimeout)
+		cmd = exec.Command("systemd-run", "--unit", "sriov-network-config-daemon-reboot",
+			"--description", "sriov-network-config-daemon reboot node",
+			fmt.Sprintf("--property=RuntimeMaxSec=%d", int(dn.rebootShutdownTimeout.Seconds())),
+			"systemctl", "reboot")
+	} else {
+		// creates a new transient systemd unit to reboot the system.
+		// We explictily try to stop kubelet.service first, before anything else; this
+		// way we ensure the rest of system stays running, because kubelet may need
+		// to do "graceful" shutdown by e.g. de-registering with a load balancer.
+		// However note we use `;` instead of `&&` so we keep rebooting even
+		// if kubelet failed to shutdown - that way the machine will still eventually reboot
+		// as systemd will time out the stop invocation.
+		cmd = exec.Command("systemd-run", "--unit", "sriov-network-config-daemon-reboot",
+			"--description", "sriov-network-config-daemon reboot node", "/bin/sh", "-c", "systemctl stop kubelet.service; reboot")
+	}
 
 	if err := cmd.Run(); err != nil {
 		log.Log.Error(err, "failed to reboot node")
 	}
 }
+
+// ClearShutdownDelay removes the sentinel file rebootNode() drops before triggering a reboot. It
+// must be called once the daemon's own preStop drain has finished, so the container's preStop
+// hook is the intended caller; other SR-IOV consumers can poll for the sentinel's absence in their
+// own preStop hooks before letting kubelet finish terminating their pod.
+func ClearShutdownDelay() error {
+	if err := os.Remove(shutdownDelayFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// hostCleanupPaths are the systemd unit, dropin and udev rule files the daemon may have written to
+// the host while running with useSystemdService. Cleanup() removes every one of them.
+var hostCleanupPaths = []string{
+	"/etc/systemd/system/sriov-config.service",
+	"/etc/systemd/system/sriov-config.service.d",
+	"/etc/systemd/system/sriov-config-post-network.service",
+	"/etc/systemd/system/sriov-config-post-network.service.d",
+	"/etc/udev/rules.d/10-nm-unmanaged.rules",
+	"/etc/udev/rules.d/20-switchdev.rules",
+}
+
+// Cleanup removes every host-side artifact the daemon installs while running with
+// useSystemdService: the sriov-config/sriov-config-post-network systemd units and their dropins,
+// the generated NM/udev rules, and the on-disk PF state the store manager keeps. It is meant to
+// run once, on an explicit "operator is being uninstalled" signal (SriovOperatorConfig deletion or
+// a finalizer - wired up by the daemon's entrypoint, outside this package) rather than on every
+// normal termination, and is idempotent: every step tolerates its target already being gone, so
+// calling it twice (e.g. a retry after the grace period expired) is safe.
+func (dn *Daemon) Cleanup() error {
+	if !dn.useSystemdService {
+		return nil
+	}
+
+	log.Log.Info("Cleanup(): removing host-side systemd units and generated rules")
+
+	exit, err := utils.Chroot("/host")
+	if err != nil {
+		return fmt.Errorf("Cleanup(): chroot command failed: %w", err)
+	}
+	defer exit()
+
+	for _, unit := range []string{"sriov-config.service", "sriov-config-post-network.service"} {
+		if err := exec.Command("systemctl", "disable", "--now", unit).Run(); err != nil {
+			log.Log.V(2).Info("Cleanup(): failed to disable systemd unit, continuing", "unit", unit, "error", err)
+		}
+	}
+
+	for _, p := range hostCleanupPaths {
+		if err := os.RemoveAll(p); err != nil && !os.IsNotExist(err) {
+			log.Log.Error(err, "Cleanup(): failed to remove host artifact", "path", p)
+		}
+	}
+
+	if err := exec.Command("systemctl", "daemon-reload").Run(); err != nil {
+		log.Log.Error(err, "Cleanup(): failed to reload systemd units")
+	}
+
+	if err := dn.storeManager.ClearPCIAddressFolder(); err != nil {
+		log.Log.Error(err, "Cleanup(): failed to clear PCI address state")
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+// Package drain holds small, controller-independent helpers for the node drain reconcile loop.
+package drain
+
+import (
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	consts "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+// CountDraining returns how many of nodeStates are currently mid-drain, i.e. have their
+// NodeStateDrainAnnotationCurrent annotation set to DrainComplete.
+func CountDraining(nodeStates []sriovnetworkv1.SriovNetworkNodeState) int {
+	count := 0
+	for i := range nodeStates {
+		if nodeStates[i].Annotations[consts.NodeStateDrainAnnotationCurrent] == consts.DrainComplete {
+			count++
+		}
+	}
+	return count
+}
+
+// AllowsMoreDrains reports whether one more node may transition into DrainComplete given the
+// cluster-wide SriovOperatorConfigSpec.MaxParallelNodeConfiguration cap (0 means unlimited).
+// DrainReconcile checks this as an outer bound on top of each pool's own MaxUnavailable, and
+// requeues the request instead of proceeding when it returns false.
+func AllowsMoreDrains(maxParallel, currentlyDraining int) bool {
+	if maxParallel <= 0 {
+		return true
+	}
+	return currentlyDraining < maxParallel
+}
@@ -0,0 +1,39 @@
+package drain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	consts "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+)
+
+func nodeStateWithDrainAnnotation(name, value string) sriovnetworkv1.SriovNetworkNodeState {
+	return sriovnetworkv1.SriovNetworkNodeState{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{consts.NodeStateDrainAnnotationCurrent: value},
+		},
+	}
+}
+
+func TestCountDraining_CountsOnlyDrainComplete(t *testing.T) {
+	nodeStates := []sriovnetworkv1.SriovNetworkNodeState{
+		nodeStateWithDrainAnnotation("node1", consts.DrainComplete),
+		nodeStateWithDrainAnnotation("node2", consts.DrainIdle),
+		nodeStateWithDrainAnnotation("node3", consts.DrainComplete),
+	}
+
+	assert.Equal(t, 2, CountDraining(nodeStates))
+}
+
+func TestAllowsMoreDrains_ZeroMeansUnlimited(t *testing.T) {
+	assert.True(t, AllowsMoreDrains(0, 100))
+}
+
+func TestAllowsMoreDrains_DeniesAtCap(t *testing.T) {
+	assert.True(t, AllowsMoreDrains(2, 1))
+	assert.False(t, AllowsMoreDrains(2, 2))
+}
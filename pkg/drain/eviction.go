@@ -0,0 +1,65 @@
+package drain
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Phase values for SriovNetworkNodeState.Status.DrainStatus.Phase.
+const (
+	DrainStatusPhaseIdle     = "Idle"
+	DrainStatusPhaseEvicting = "Evicting"
+	DrainStatusPhaseComplete = "Complete"
+	DrainStatusPhaseFailed   = "Failed"
+)
+
+const (
+	minEvictionBackoff = 5 * time.Second
+	maxEvictionBackoff = 2 * time.Minute
+)
+
+// BlockingPod identifies a pod whose PodDisruptionBudget is currently preventing eviction.
+type BlockingPod struct {
+	Namespace string
+	Name      string
+	Reason    string
+}
+
+// DrainStatus is the per-node eviction progress DrainReconcile writes to
+// SriovNetworkNodeState.Status.DrainStatus, so operators can see which pods are blocking a stuck
+// drain instead of only a timestamp-less "draining" annotation.
+type DrainStatus struct {
+	Phase             string
+	StartedAt         *metav1.Time
+	BlockingPods      []BlockingPod
+	LastEvictionError string
+}
+
+// NextEvictionBackoff returns how long DrainReconcile should wait before retrying eviction after
+// attempt PDB-blocked eviction rounds, growing exponentially from 5s up to a 2m cap. attempt values
+// below 1 are treated as 1.
+func NextEvictionBackoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	backoff := minEvictionBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= maxEvictionBackoff {
+			return maxEvictionBackoff
+		}
+	}
+	return backoff
+}
+
+// DrainTimedOut reports whether a drain that started at startedAt has run longer than timeout, the
+// pool's SriovNetworkPoolConfigSpec.DrainTimeout. A non-positive timeout means no deadline, so the
+// drain never times out.
+func DrainTimedOut(startedAt, now time.Time, timeout time.Duration) bool {
+	if timeout <= 0 {
+		return false
+	}
+	return now.Sub(startedAt) >= timeout
+}
@@ -0,0 +1,28 @@
+package drain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextEvictionBackoff_GrowsExponentiallyAndCaps(t *testing.T) {
+	assert.Equal(t, 5*time.Second, NextEvictionBackoff(1))
+	assert.Equal(t, 10*time.Second, NextEvictionBackoff(2))
+	assert.Equal(t, 20*time.Second, NextEvictionBackoff(3))
+	assert.Equal(t, 2*time.Minute, NextEvictionBackoff(10))
+}
+
+func TestNextEvictionBackoff_ClampsNonPositiveAttempt(t *testing.T) {
+	assert.Equal(t, 5*time.Second, NextEvictionBackoff(0))
+	assert.Equal(t, 5*time.Second, NextEvictionBackoff(-3))
+}
+
+func TestDrainTimedOut(t *testing.T) {
+	start := time.Now()
+
+	assert.False(t, DrainTimedOut(start, start.Add(time.Minute), 5*time.Minute))
+	assert.True(t, DrainTimedOut(start, start.Add(5*time.Minute), 5*time.Minute))
+	assert.False(t, DrainTimedOut(start, start.Add(time.Hour), 0))
+}
@@ -0,0 +1,63 @@
+package drain
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Event reasons DrainReconcile records against the Node and SriovNetworkNodeState objects it
+// drains, so the drain lifecycle shows up in `kubectl describe` alongside the metrics below.
+const (
+	EventReasonDrainStarted        = "DrainStarted"
+	EventReasonDrainCompleted      = "DrainCompleted"
+	EventReasonDrainFailed         = "DrainFailed"
+	EventReasonPoolCapacityReached = "PoolCapacityReached"
+)
+
+var (
+	// DrainInProgress is 1 for every node currently mid-drain in pool, 0 once it settles back to
+	// DrainIdle.
+	DrainInProgress = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sriov_drain_in_progress",
+		Help: "Whether a node is currently being drained by the SR-IOV operator (1) or not (0)",
+	}, []string{"pool", "node"})
+
+	// DrainDurationSeconds observes how long one DrainRequired->DrainIdle cycle took, labeled by
+	// its outcome so failed/timed-out drains don't skew the success-path latency.
+	DrainDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sriov_drain_duration_seconds",
+		Help:    "Duration of a node drain cycle, from DrainRequired to DrainIdle",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12), // 1s .. ~34m
+	}, []string{"pool", "result"})
+
+	// DrainTotal counts completed drain cycles by outcome.
+	DrainTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sriov_drain_total",
+		Help: "Total number of node drain cycles, by result",
+	}, []string{"pool", "result"})
+
+	// PoolMaxUnavailable mirrors each pool's configured MaxUnavailable so it can be compared
+	// directly against sriov_drain_in_progress in dashboards and alerts.
+	PoolMaxUnavailable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sriov_pool_max_unavailable",
+		Help: "Configured MaxUnavailable for a SriovNetworkPoolConfig pool",
+	}, []string{"pool"})
+)
+
+// RegisterMetrics registers the drain lifecycle metrics with controller-runtime's metrics
+// registry. DrainReconcile.SetupWithManager calls this once during controller setup.
+func RegisterMetrics() {
+	metrics.Registry.MustRegister(
+		DrainInProgress,
+		DrainDurationSeconds,
+		DrainTotal,
+		PoolMaxUnavailable,
+	)
+}
+
+// Drain outcome labels for DrainDurationSeconds/DrainTotal's "result" label.
+const (
+	DrainResultSucceeded = "succeeded"
+	DrainResultFailed    = "failed"
+	DrainResultTimedOut  = "timed_out"
+)
@@ -0,0 +1,42 @@
+package drain
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DrainMode selects how much disruption DrainReconcile causes on a node before applying SR-IOV
+// configuration.
+type DrainMode string
+
+const (
+	// DrainModeFull cordons and fully drains the node, evicting every evictable pod.
+	DrainModeFull DrainMode = "Full"
+	// DrainModeCordonOnly only cordons the node; no pods are evicted.
+	DrainModeCordonOnly DrainMode = "CordonOnly"
+	// DrainModeEvictSRIOVOnly cordons the node and evicts only pods that request SR-IOV resources,
+	// leaving unrelated workloads running so policy updates that don't need a reboot cause a much
+	// shorter outage window.
+	DrainModeEvictSRIOVOnly DrainMode = "EvictSRIOVOnly"
+)
+
+// DefaultSRIOVResourcePrefixes are the resource-name prefixes RequestsSRIOVResource checks when a
+// pool doesn't configure its own list.
+var DefaultSRIOVResourcePrefixes = []string{"openshift.io/", "nvidia.com/"}
+
+// RequestsSRIOVResource reports whether any container in pod requests a resource whose name starts
+// with one of prefixes. DrainModeEvictSRIOVOnly uses this to pick which pods to evict on an
+// otherwise cordoned-only node.
+func RequestsSRIOVResource(pod *corev1.Pod, prefixes []string) bool {
+	for _, container := range pod.Spec.Containers {
+		for resourceName := range container.Resources.Requests {
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(string(resourceName), prefix) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
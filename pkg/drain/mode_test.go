@@ -0,0 +1,37 @@
+package drain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func podRequesting(resourceName string) *corev1.Pod {
+	return &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceName(resourceName): resource.MustParse("1"),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRequestsSRIOVResource_MatchesConfiguredPrefix(t *testing.T) {
+	pod := podRequesting("openshift.io/mlx_sriov")
+
+	assert.True(t, RequestsSRIOVResource(pod, DefaultSRIOVResourcePrefixes))
+}
+
+func TestRequestsSRIOVResource_NoMatchForPlainResource(t *testing.T) {
+	pod := podRequesting("cpu")
+
+	assert.False(t, RequestsSRIOVResource(pod, DefaultSRIOVResourcePrefixes))
+}
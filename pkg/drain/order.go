@@ -0,0 +1,69 @@
+package drain
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DrainOrder selects how nodes within one pool are ordered when more than one of them is currently
+// requesting a drain, controlling which node the pool's MaxUnavailable budget admits first.
+type DrainOrder string
+
+const (
+	DrainOrderAlphabetical   DrainOrder = "Alphabetical"
+	DrainOrderPriority       DrainOrder = "Priority"
+	DrainOrderLeastPodsFirst DrainOrder = "LeastPodsFirst"
+	DrainOrderMostReadyFirst DrainOrder = "MostReadyFirst"
+
+	// DrainPriorityAnnotation is an optional integer annotation on a Node; higher values drain
+	// first under DrainOrderPriority. Nodes without it, or with a non-integer value, are priority 0.
+	DrainPriorityAnnotation = "sriovnetwork.openshift.io/drain-priority"
+)
+
+// NodeCandidate is one node currently requesting a drain, with the data SortCandidates needs to
+// order it relative to its pool siblings.
+type NodeCandidate struct {
+	Node *corev1.Node
+	// PodCount is the number of pods currently scheduled on Node, used by DrainOrderLeastPodsFirst.
+	PodCount int
+	// ReadyFor is how long Node has been Ready; higher sorts first under DrainOrderMostReadyFirst.
+	ReadyFor time.Duration
+}
+
+// SortCandidates orders candidates in place according to order. Every order falls back to a stable
+// alphabetical-by-name tiebreaker whenever it doesn't otherwise distinguish two nodes.
+func SortCandidates(candidates []NodeCandidate, order DrainOrder) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		switch order {
+		case DrainOrderPriority:
+			pi, pj := drainPriority(candidates[i].Node), drainPriority(candidates[j].Node)
+			if pi != pj {
+				return pi > pj
+			}
+		case DrainOrderLeastPodsFirst:
+			if candidates[i].PodCount != candidates[j].PodCount {
+				return candidates[i].PodCount < candidates[j].PodCount
+			}
+		case DrainOrderMostReadyFirst:
+			if candidates[i].ReadyFor != candidates[j].ReadyFor {
+				return candidates[i].ReadyFor > candidates[j].ReadyFor
+			}
+		}
+		return candidates[i].Node.Name < candidates[j].Node.Name
+	})
+}
+
+func drainPriority(node *corev1.Node) int {
+	raw, ok := node.Annotations[DrainPriorityAnnotation]
+	if !ok {
+		return 0
+	}
+	priority, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0
+	}
+	return priority
+}
@@ -0,0 +1,62 @@
+package drain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSortCandidates_Priority(t *testing.T) {
+	node1 := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Annotations: map[string]string{DrainPriorityAnnotation: "5"}}}
+	node2 := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2", Annotations: map[string]string{DrainPriorityAnnotation: "10"}}}
+	node3 := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node3"}}
+
+	candidates := []NodeCandidate{{Node: node1}, {Node: node2}, {Node: node3}}
+	SortCandidates(candidates, DrainOrderPriority)
+
+	assert.Equal(t, []string{"node2", "node1", "node3"}, names(candidates))
+}
+
+func TestSortCandidates_Alphabetical(t *testing.T) {
+	candidates := []NodeCandidate{
+		{Node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node3"}}},
+		{Node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}},
+		{Node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2"}}},
+	}
+	SortCandidates(candidates, DrainOrderAlphabetical)
+
+	assert.Equal(t, []string{"node1", "node2", "node3"}, names(candidates))
+}
+
+func TestSortCandidates_LeastPodsFirst(t *testing.T) {
+	candidates := []NodeCandidate{
+		{Node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}, PodCount: 5},
+		{Node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2"}}, PodCount: 1},
+		{Node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node3"}}, PodCount: 3},
+	}
+	SortCandidates(candidates, DrainOrderLeastPodsFirst)
+
+	assert.Equal(t, []string{"node2", "node3", "node1"}, names(candidates))
+}
+
+func TestSortCandidates_MostReadyFirst(t *testing.T) {
+	candidates := []NodeCandidate{
+		{Node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}, ReadyFor: time.Hour},
+		{Node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2"}}, ReadyFor: 24 * time.Hour},
+		{Node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node3"}}, ReadyFor: time.Minute},
+	}
+	SortCandidates(candidates, DrainOrderMostReadyFirst)
+
+	assert.Equal(t, []string{"node2", "node1", "node3"}, names(candidates))
+}
+
+func names(candidates []NodeCandidate) []string {
+	result := make([]string, len(candidates))
+	for i, c := range candidates {
+		result[i] = c.Node.Name
+	}
+	return result
+}
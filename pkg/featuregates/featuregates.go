@@ -0,0 +1,42 @@
+// Package featuregates is a process-wide registry of named on/off switches for risky or
+// in-development code paths, fed from SriovOperatorConfigSpec.FeatureGates so operators can
+// flip them per-cluster without rebuilding the daemon or plugin images.
+package featuregates
+
+import "sync"
+
+// Names of the feature gates this package knows about. Every one of them defaults to off until
+// Replace is called with a map that turns it on.
+const (
+	ManageSoftwareBridges = "manageSoftwareBridges"
+	ParallelNicConfig     = "parallelNicConfig"
+	MellanoxFirmwareReset = "mellanoxFirmwareReset"
+	MetricsExporter       = "metricsExporter"
+)
+
+var (
+	mu    sync.RWMutex
+	gates = map[string]bool{}
+)
+
+// Replace atomically swaps in a new set of feature gates, e.g. when the daemon observes a
+// SriovOperatorConfig update. Any gate missing from newGates is treated as disabled.
+func Replace(newGates map[string]bool) {
+	next := make(map[string]bool, len(newGates))
+	for name, enabled := range newGates {
+		next[name] = enabled
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	gates = next
+}
+
+// Enabled reports whether the named feature gate is currently turned on. Unrecognized names
+// report false, matching the "ship disabled" default for gates this package hasn't been told
+// about yet.
+func Enabled(name string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return gates[name]
+}
@@ -0,0 +1,22 @@
+package featuregates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnabledDefaultsToFalse(t *testing.T) {
+	Replace(nil)
+	assert.False(t, Enabled(ManageSoftwareBridges))
+	assert.False(t, Enabled("unknown-gate"))
+}
+
+func TestReplaceTogglesGate(t *testing.T) {
+	Replace(map[string]bool{ManageSoftwareBridges: true})
+	assert.True(t, Enabled(ManageSoftwareBridges))
+	assert.False(t, Enabled(ParallelNicConfig))
+
+	Replace(map[string]bool{ManageSoftwareBridges: false})
+	assert.False(t, Enabled(ManageSoftwareBridges))
+}
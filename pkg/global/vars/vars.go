@@ -36,6 +36,16 @@ var (
 
 	DpdkDrivers = []string{"igb_uio", "vfio-pci", "uio_pci_generic"}
 
+	// VdpaDrivers are the valid VfGroup.DeviceType values that put a VF's vdpa device (rather
+	// than the VF itself) in userspace-facing mode; see pkg/host/vdpa
+	VdpaDrivers = []string{"vhost_vdpa", "virtio_vdpa"}
+
+	// NetworkManagerBackendOverride forces the udev-backend used to keep NetworkManager (or its
+	// equivalent) from managing operator-owned PFs/VFs, overriding auto-detection. One of
+	// "NetworkManager", "systemd-networkd", "none", or "" (auto-detect). Set from the
+	// --network-manager-backend config-daemon flag.
+	NetworkManagerBackendOverride string
+
 	//Cluster variables
 	Config *rest.Config
 	Scheme *runtime.Scheme
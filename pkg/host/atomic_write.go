@@ -0,0 +1,57 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// atomicWriteFile writes data to path by writing a sibling temp file in the same directory,
+// fsyncing it, renaming it over path, then fsyncing the parent directory. This keeps a crash
+// mid-write from leaving another reader of path (systemd's configure-switchdev.sh, udev, ...)
+// with a truncated or partially-written file.
+func atomicWriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath) // no-op once the rename below has succeeded
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	return fsyncDir(dir)
+}
+
+// atomicRemoveFile removes path and fsyncs its parent directory, so the removal itself is
+// crash-consistent instead of risking the file reappearing after an unclean shutdown.
+func atomicRemoveFile(path string) error {
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	return fsyncDir(filepath.Dir(path))
+}
+
+func fsyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -1,86 +1,200 @@
 package host
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/vishvananda/netlink"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
-	dputils "github.com/k8snetworkplumbingwg/sriov-network-device-plugin/pkg/utils"
-
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/busdriver"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/dputils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/sysfs"
 )
 
-// Unbind unbind driver for one device
-func (h *HostManager) Unbind(pciAddr string) error {
-	log.Log.V(2).Info("Unbind(): unbind device driver for device", "device", pciAddr)
-	yes, driver := h.HasDriver(pciAddr)
+// ErrNoDriverBound is returned by UnbindDriverByBusAndDevice (and Unbind) when device has no
+// driver currently bound, so callers can tell "there was nothing to unbind" apart from "unbind
+// failed". It's defined in busdriver, not here, so pkg/host/vdpa can check it too without
+// importing this package back.
+var ErrNoDriverBound = busdriver.ErrNoDriverBound
+
+// writer performs the sysfs control-file writes below (unbind, bind, driver_override,
+// drivers_probe); it's a package-level var rather than a field so tests can swap in
+// sysfs.NewFake(afero.NewMemMapFs()) without threading a Writer through every constructor.
+var writer sysfs.Writer = sysfs.New()
+
+// dpUtils wraps the sriov-network-device-plugin driver/VF lookups used below; it's a
+// package-level var, like writer above, so tests can swap in a fake DPUtilsLib.
+var dpUtils dputils.DPUtilsLib = dputils.New()
+
+// sysBusDriversDir returns the .../drivers directory for the given bus, e.g.
+// "/sys/bus/pci/drivers" for consts.BusPci.
+func sysBusDriversDir(bus string) string {
+	return filepath.Join(vars.FilesystemRoot, consts.SysBus, bus, "drivers")
+}
+
+// sysBusDevicesDir returns the .../devices directory for the given bus, e.g.
+// "/sys/bus/pci/devices" for consts.BusPci.
+func sysBusDevicesDir(bus string) string {
+	return filepath.Join(vars.FilesystemRoot, consts.SysBus, bus, "devices")
+}
+
+// UnbindDriverByBusAndDevice unbinds device from whatever driver it's currently bound to on the
+// given sysfs bus (consts.BusPci, consts.BusVdpa, ...). It returns ErrNoDriverBound if the device
+// has no driver.
+func (h *HostManager) UnbindDriverByBusAndDevice(bus, device string) error {
+	log.Log.V(2).Info("UnbindDriverByBusAndDevice(): unbind device driver", "bus", bus, "device", device)
+	yes, driver := h.HasDriverByBusAndDevice(bus, device)
 	if !yes {
-		return nil
+		return ErrNoDriverBound
 	}
 
-	filePath := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDrivers, driver, "unbind")
-	err := os.WriteFile(filePath, []byte(pciAddr), os.ModeAppend)
+	filePath := filepath.Join(sysBusDriversDir(bus), driver, "unbind")
+	err := writer.WriteFile(filePath, []byte(device))
 	if err != nil {
-		log.Log.Error(err, "Unbind(): fail to unbind driver for device", "device", pciAddr)
+		log.Log.Error(err, "UnbindDriverByBusAndDevice(): fail to unbind driver", "bus", bus, "device", device)
 		return err
 	}
 	return nil
 }
 
-// BindDpdkDriver bind dpdk driver for one device
-// Bind the device given by "pciAddr" to the driver "driver"
-func (h *HostManager) BindDpdkDriver(pciAddr, driver string) error {
-	log.Log.V(2).Info("BindDpdkDriver(): bind device to driver",
-		"device", pciAddr, "driver", driver)
+// BindDriverByBusAndDevice binds device to driver on the given sysfs bus (consts.BusPci,
+// consts.BusVdpa, ...) via the driver_override + bind dance, unbinding any driver device is
+// currently bound to first.
+func (h *HostManager) BindDriverByBusAndDevice(bus, device, driver string) error {
+	log.Log.V(2).Info("BindDriverByBusAndDevice(): bind device to driver", "bus", bus, "device", device, "driver", driver)
 
-	if yes, d := h.HasDriver(pciAddr); yes {
+	if yes, d := h.HasDriverByBusAndDevice(bus, device); yes {
 		if driver == d {
-			log.Log.V(2).Info("BindDpdkDriver(): device already bound to driver",
-				"device", pciAddr, "driver", driver)
+			log.Log.V(2).Info("BindDriverByBusAndDevice(): device already bound to driver",
+				"bus", bus, "device", device, "driver", driver)
 			return nil
 		}
 
-		if err := h.Unbind(pciAddr); err != nil {
+		if err := h.UnbindDriverByBusAndDevice(bus, device); err != nil {
 			return err
 		}
 	}
 
-	driverOverridePath := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, "driver_override")
-	err := os.WriteFile(driverOverridePath, []byte(driver), os.ModeAppend)
+	driverOverridePath := filepath.Join(sysBusDevicesDir(bus), device, "driver_override")
+	err := writer.WriteFile(driverOverridePath, []byte(driver))
 	if err != nil {
-		log.Log.Error(err, "BindDpdkDriver(): fail to write driver_override for device",
-			"device", pciAddr, "driver", driver)
+		log.Log.Error(err, "BindDriverByBusAndDevice(): fail to write driver_override",
+			"bus", bus, "device", device, "driver", driver)
 		return err
 	}
-	bindPath := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDrivers, driver, "bind")
-	err = os.WriteFile(bindPath, []byte(pciAddr), os.ModeAppend)
+	bindPath := filepath.Join(sysBusDriversDir(bus), driver, "bind")
+	err = writer.WriteFile(bindPath, []byte(device))
 	if err != nil {
-		log.Log.Error(err, "BindDpdkDriver(): fail to bind driver for device",
-			"driver", driver, "device", pciAddr)
-		_, err := os.Readlink(filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, "iommu_group"))
-		if err != nil {
-			log.Log.Error(err, "Could not read IOMMU group for device", "device", pciAddr)
-			return fmt.Errorf(
-				"cannot bind driver %s to device %s, make sure IOMMU is enabled in BIOS. %w", driver, pciAddr, err)
+		log.Log.Error(err, "BindDriverByBusAndDevice(): fail to bind driver",
+			"bus", bus, "driver", driver, "device", device)
+		if bus == consts.BusPci {
+			_, err := os.Readlink(filepath.Join(sysBusDevicesDir(bus), device, "iommu_group"))
+			if err != nil {
+				log.Log.Error(err, "Could not read IOMMU group for device", "device", device)
+				return fmt.Errorf(
+					"cannot bind driver %s to device %s, make sure IOMMU is enabled in BIOS. %w", driver, device, err)
+			}
 		}
 		return err
 	}
-	err = os.WriteFile(driverOverridePath, []byte(""), os.ModeAppend)
+	err = writer.WriteFile(driverOverridePath, []byte(""))
 	if err != nil {
-		log.Log.Error(err, "BindDpdkDriver(): failed to clear driver_override for device", "device", pciAddr)
+		log.Log.Error(err, "BindDriverByBusAndDevice(): failed to clear driver_override", "bus", bus, "device", device)
 		return err
 	}
 
 	return nil
 }
 
+// HasDriverByBusAndDevice returns true and the driver name if device is currently bound to a
+// driver on the given sysfs bus.
+func (h *HostManager) HasDriverByBusAndDevice(bus, device string) (bool, string) {
+	driverLink := filepath.Join(sysBusDevicesDir(bus), device, "driver")
+	target, err := os.Readlink(driverLink)
+	if err != nil {
+		log.Log.V(2).Info("HasDriverByBusAndDevice(): device driver is empty for device", "bus", bus, "device", device)
+		return false, ""
+	}
+	driver := filepath.Base(target)
+	log.Log.V(2).Info("HasDriverByBusAndDevice(): device driver for device", "bus", bus, "device", device, "driver", driver)
+	return true, driver
+}
+
+// Unbind unbind driver for one device
+func (h *HostManager) Unbind(pciAddr string) error {
+	return h.UnbindDriverByBusAndDevice(consts.BusPci, pciAddr)
+}
+
+// BindDpdkDriver bind dpdk driver for one device
+// Bind the device given by "pciAddr" to the driver "driver"
+func (h *HostManager) BindDpdkDriver(pciAddr, driver string) error {
+	return h.BindDriverByBusAndDevice(consts.BusPci, pciAddr, driver)
+}
+
+// vfIsReadyTimeout bounds how long BindDefaultDriver waits for a VF's netdev to come up after
+// binding before falling back to the RebindVfToDefaultDriver workaround.
+const vfIsReadyTimeout = 10 * time.Second
+
+// vfNetdevName returns the name of the netdev under consts.SysBusPciDevices/<pciAddr>/net/, if
+// any has appeared yet.
+func vfNetdevName(pciAddr string) (string, bool) {
+	netDir := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, "net")
+	entries, err := os.ReadDir(netDir)
+	if err != nil || len(entries) == 0 {
+		return "", false
+	}
+	return entries[0].Name(), true
+}
+
+// vfIsReady polls, with backoff, for the VF's netdev to appear under
+// consts.SysBusPciDevices/<pciAddr>/net/, returning the resulting netlink.Link once it shows up.
+func vfIsReady(pciAddr string, timeout time.Duration) (netlink.Link, error) {
+	deadline := time.Now().Add(timeout)
+	interval := 50 * time.Millisecond
+	for {
+		if name, ok := vfNetdevName(pciAddr); ok {
+			return netlink.LinkByName(name)
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("VF %s netdev did not come up within %s", pciAddr, timeout)
+		}
+		if interval > remaining {
+			interval = remaining
+		}
+		time.Sleep(interval)
+		if interval *= 2; interval > time.Second {
+			interval = time.Second
+		}
+	}
+}
+
 // BindDefaultDriver bind driver for one device
 // Bind the device given by "pciAddr" to the default driver
 func (h *HostManager) BindDefaultDriver(pciAddr string) error {
+	if err := h.bindDefaultDriver(pciAddr); err != nil {
+		return err
+	}
+
+	if _, err := vfIsReady(pciAddr, vfIsReadyTimeout); err != nil {
+		log.Log.Info("BindDefaultDriver(): VF did not come up after binding, rebinding to default driver",
+			"device", pciAddr, "reason", err.Error())
+		return h.RebindVfToDefaultDriver(pciAddr)
+	}
+	return nil
+}
+
+// bindDefaultDriver does the actual driver_override+drivers_probe dance. It's kept separate from
+// BindDefaultDriver so RebindVfToDefaultDriver can re-bind without re-entering the
+// vfIsReady/rebind check that BindDefaultDriver itself performs.
+func (h *HostManager) bindDefaultDriver(pciAddr string) error {
 	log.Log.V(2).Info("BindDefaultDriver(): bind device to default driver", "device", pciAddr)
 
 	if yes, d := h.HasDriver(pciAddr); yes {
@@ -95,14 +209,14 @@ func (h *HostManager) BindDefaultDriver(pciAddr string) error {
 	}
 
 	driverOverridePath := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, "driver_override")
-	err := os.WriteFile(driverOverridePath, []byte("\x00"), os.ModeAppend)
+	err := writer.WriteFile(driverOverridePath, []byte("\x00"))
 	if err != nil {
 		log.Log.Error(err, "BindDefaultDriver(): failed to write driver_override for device", "device", pciAddr)
 		return err
 	}
 
 	pciDriversProbe := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDriversProbe)
-	err = os.WriteFile(pciDriversProbe, []byte(pciAddr), os.ModeAppend)
+	err = writer.WriteFile(pciDriversProbe, []byte(pciAddr))
 	if err != nil {
 		log.Log.Error(err, "BindDefaultDriver(): failed to bind driver for device", "device", pciAddr)
 		return err
@@ -116,10 +230,10 @@ func (h *HostManager) BindDefaultDriver(pciAddr string) error {
 // bugzilla: https://bugzilla.redhat.com/show_bug.cgi?id=2045087
 func (h *HostManager) RebindVfToDefaultDriver(vfAddr string) error {
 	log.Log.Info("RebindVfToDefaultDriver()", "vf", vfAddr)
-	if err := h.Unbind(vfAddr); err != nil {
+	if err := h.Unbind(vfAddr); err != nil && !errors.Is(err, ErrNoDriverBound) {
 		return err
 	}
-	if err := h.BindDefaultDriver(vfAddr); err != nil {
+	if err := h.bindDefaultDriver(vfAddr); err != nil {
 		log.Log.Error(err, "RebindVfToDefaultDriver(): fail to bind default driver", "device", vfAddr)
 		return err
 	}
@@ -128,10 +242,14 @@ func (h *HostManager) RebindVfToDefaultDriver(vfAddr string) error {
 	return nil
 }
 
+// UnbindDriverIfNeeded unloads the VF's current kernel driver only when the VF belongs to an
+// RDMA-enabled VfGroup (group.IsRdma). Reloading the driver is what picks up module parameters
+// such as hfi1/mlx5_ib's RDMA settings, so skipping it for non-RDMA groups avoids an expensive
+// unbind/rebind cycle (including the VFIsReady wait) that would otherwise run on every VF.
 func (h *HostManager) UnbindDriverIfNeeded(vfAddr string, isRdma bool) error {
 	if isRdma {
 		log.Log.Info("UnbindDriverIfNeeded(): unbinding driver", "device", vfAddr)
-		if err := h.Unbind(vfAddr); err != nil {
+		if err := h.Unbind(vfAddr); err != nil && !errors.Is(err, ErrNoDriverBound) {
 			return err
 		}
 		log.Log.Info("UnbindDriverIfNeeded(): unbounded driver", "device", vfAddr)
@@ -140,7 +258,7 @@ func (h *HostManager) UnbindDriverIfNeeded(vfAddr string, isRdma bool) error {
 }
 
 func (h *HostManager) HasDriver(pciAddr string) (bool, string) {
-	driver, err := dputils.GetDriverName(pciAddr)
+	driver, err := dpUtils.GetDriverName(pciAddr)
 	if err != nil {
 		log.Log.V(2).Info("HasDriver(): device driver is empty for device", "device", pciAddr)
 		return false, ""
@@ -148,3 +266,10 @@ func (h *HostManager) HasDriver(pciAddr string) (bool, string) {
 	log.Log.V(2).Info("HasDriver(): device driver for device", "device", pciAddr, "driver", driver)
 	return true, driver
 }
+
+// HasUserspaceDriver returns true if pciAddr is currently bound to one of vars.DpdkDrivers
+// (e.g. vfio-pci), centralizing the scattered "HasDriver + is it a DPDK driver" checks.
+func (h *HostManager) HasUserspaceDriver(pciAddr string) bool {
+	hasDriver, driver := h.HasDriver(pciAddr)
+	return hasDriver && sriovnetworkv1.StringInArray(driver, vars.DpdkDrivers)
+}
@@ -0,0 +1,84 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/dputils"
+)
+
+// fakeDPUtils implements dputils.DPUtilsLib, embedding a nil interface so only the methods a
+// given test needs are overridden.
+type fakeDPUtils struct {
+	dputils.DPUtilsLib
+	driverName    string
+	driverNameErr error
+}
+
+func (f *fakeDPUtils) GetDriverName(pciAddr string) (string, error) {
+	return f.driverName, f.driverNameErr
+}
+
+// setupSysfsTestFS points vars.FilesystemRoot at a scratch directory, so the bind/unbind sysfs
+// control-file paths resolve under it without touching the real host.
+func setupSysfsTestFS(t *testing.T) {
+	vars.FilesystemRoot = t.TempDir()
+	t.Cleanup(func() { vars.FilesystemRoot = "" })
+}
+
+func TestVfNetdevNameMissing(t *testing.T) {
+	setupSysfsTestFS(t)
+
+	_, ok := vfNetdevName("0000:3b:00.1")
+	assert.False(t, ok)
+}
+
+func TestVfNetdevNameAppears(t *testing.T) {
+	setupSysfsTestFS(t)
+
+	netDir := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, "0000:3b:00.1", "net")
+	require.NoError(t, os.MkdirAll(netDir, 0755))
+	require.NoError(t, os.Mkdir(filepath.Join(netDir, "eth1"), 0755))
+
+	name, ok := vfNetdevName("0000:3b:00.1")
+	require.True(t, ok)
+	assert.Equal(t, "eth1", name)
+}
+
+func TestVfIsReadyTimesOutWithoutNetdev(t *testing.T) {
+	setupSysfsTestFS(t)
+
+	_, err := vfIsReady("0000:3b:00.1", 300*time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestUnbindDriverByBusAndDeviceNoDriverBound(t *testing.T) {
+	setupSysfsTestFS(t)
+	h := &HostManager{}
+
+	err := h.UnbindDriverByBusAndDevice(consts.BusPci, "0000:3b:00.1")
+	assert.ErrorIs(t, err, ErrNoDriverBound)
+}
+
+func TestHasDriver(t *testing.T) {
+	orig := dpUtils
+	defer func() { dpUtils = orig }()
+	h := &HostManager{}
+
+	dpUtils = &fakeDPUtils{driverName: "vfio-pci"}
+	has, driver := h.HasDriver("0000:3b:00.1")
+	assert.True(t, has)
+	assert.Equal(t, "vfio-pci", driver)
+
+	dpUtils = &fakeDPUtils{driverNameErr: assert.AnError}
+	has, driver = h.HasDriver("0000:3b:00.1")
+	assert.False(t, has)
+	assert.Empty(t, driver)
+}
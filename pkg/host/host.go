@@ -18,7 +18,7 @@ package host
 import (
 	"fmt"
 	"os"
-	pathlib "path"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -30,22 +30,9 @@ import (
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
-	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
-const (
-	hostPathFromDaemon    = consts.Host
-	redhatReleaseFile     = "/etc/redhat-release"
-	rhelRDMAConditionFile = "/usr/libexec/rdma-init-kernel"
-	rhelRDMAServiceName   = "rdma"
-	rhelPackageManager    = "yum"
-
-	ubuntuRDMAConditionFile = "/usr/sbin/rdma-ndd"
-	ubuntuRDMAServiceName   = "rdma-ndd"
-	ubuntuPackageManager    = "apt-get"
-
-	genericOSReleaseFile = "/etc/os-release"
-)
+const hostPathFromDaemon = consts.Host
 
 // Contains all the host manipulation functions
 //
@@ -55,10 +42,18 @@ type HostManagerInterface interface {
 	TryEnableTun()
 	// TryEnableVhostNet load the vhost-net kernel module
 	TryEnableVhostNet()
-	// TryEnableRdma tries to enable RDMA on the machine base on the operating system
-	// if the package doesn't exist it will also will try to install it
-	// supported operating systems are RHEL RHCOS and ubuntu
-	TryEnableRdma() (bool, error)
+	// CheckRDMAEnabled reports whether the ib_core/rdma_* RDMA kernel modules are loaded.
+	// Installing rdma-core itself is out of scope: that's the admin's job, via a MachineConfig
+	// or a base image that ships it, not something the daemon can safely do to an immutable OS.
+	CheckRDMAEnabled() (bool, error)
+	// IsKernelModuleAvailable reports whether the named kernel module is already loaded or exists
+	// under /lib/modules/$(uname -r). It never attempts to load anything: it only tells a caller
+	// whether the module is usable or modprobe could succeed.
+	IsKernelModuleAvailable(name string) (bool, error)
+	// CheckRDMAUserlandAvailable reports whether rdma-core userland is present: /sys/class/infiniband
+	// is exposed by the kernel and ibv_devices is reachable on PATH. It says nothing about the
+	// RDMA kernel modules themselves; see CheckRDMAEnabled for that.
+	CheckRDMAUserlandAvailable() (bool, error)
 	// TryToGetVirtualInterfaceName tries to find the virtio interface name base on pci address
 	// used for virtual environment where we pass SR-IOV virtual function into the system
 	// supported platform openstack
@@ -68,12 +63,28 @@ type HostManagerInterface interface {
 	// GetNicSriovMode returns the interface mode
 	// supported modes SR-IOV legacy and switchdev
 	GetNicSriovMode(string) (string, error)
+	// SetNicSriovMode sets the devlink eswitch mode for a specific pci address
+	// supported modes SR-IOV legacy and switchdev
+	SetNicSriovMode(string, string) error
 	// GetPhysSwitchID returns the physical switch ID for a specific pci address
 	GetPhysSwitchID(string) (string, error)
 	// GetPhysPortName returns the physical port name for a specific pci address
 	GetPhysPortName(string) (string, error)
 	// IsSwitchdev returns true of the pci address is on switchdev mode
 	IsSwitchdev(string) bool
+	// GetVfRepresentor returns the representor netdev name for a VF of a switchdev PF,
+	// or an empty string if the PF is not in switchdev mode
+	GetVfRepresentor(pfName string, vfIndex int) (string, error)
+	// GetRDMASubsystemMode returns the kernel RDMA subsystem network-namespace mode, shared or exclusive
+	GetRDMASubsystemMode() (string, error)
+	// SetRDMASubsystemMode configures the kernel RDMA subsystem network-namespace mode. It must
+	// only be called from the systemd phasePre step, before any non-init network namespace is
+	// created on the host; see the doc comment on the implementation for details
+	SetRDMASubsystemMode(mode string) error
+	// IsLinkUp returns false if the requested netdev's operstate is "down", true otherwise
+	IsLinkUp(ifaceName string) (bool, error)
+	// GetNetDevNodeGUID returns the InfiniBand node GUID for the netdev on the given pci address
+	GetNetDevNodeGUID(pciAddr string) (string, error)
 	// IsKernelLockdownMode returns true if the kernel is in lockdown mode
 	IsKernelLockdownMode() bool
 	// GetNetdevMTU returns the interface MTU for devices attached to kernel drivers
@@ -81,8 +92,12 @@ type HostManagerInterface interface {
 	// SetNetdevMTU sets the MTU for a request interface
 	SetNetdevMTU(string, int) error
 	// SetSriovNumVfs changes the number of virtual functions allocated for a specific
-	// physical function base on pci address
+	// physical function base on pci address. Returns ErrSRIOVUnsupported if the PF isn't
+	// actually SR-IOV capable at the kernel level.
 	SetSriovNumVfs(string, int) error
+	// SRIOVCapable reports whether a PF exposes a sriov_numvfs sysfs control file, i.e.
+	// whether the kernel considers it an SR-IOV capable PF at all
+	SRIOVCapable(string) (bool, error)
 	// GetNetDevMac returns the network interface mac address
 	GetNetDevMac(string) string
 	// GetNetDevLinkSpeed returns the network interface link speed
@@ -96,6 +111,9 @@ type HostManagerInterface interface {
 	VFIsReady(string) (netlink.Link, error)
 	// SetVfAdminMac sets the virtual function administrative mac address via the physical function
 	SetVfAdminMac(string, netlink.Link, netlink.Link) error
+	// EnsureVFAddress captures the VF's current MAC (Ethernet) or node/port GUID (InfiniBand) and
+	// re-applies it on the PF side via netlink, so the address survives a driver transition
+	EnsureVFAddress(pfPciAddr, vfPciAddr, linkType string) error
 
 	// GetLinkType return the link type
 	// supported types are ethernet and infiniband
@@ -104,6 +122,9 @@ type HostManagerInterface interface {
 	ResetSriovDevice(sriovnetworkv1.InterfaceExt) error
 	// DiscoverSriovDevices returns a list of all the available SR-IOV capable network interfaces on the system
 	DiscoverSriovDevices(StoreManagerInterface) ([]sriovnetworkv1.InterfaceExt, error)
+	// HasOutOfBandDrift reports whether a managed interface has diverged from the spec in ways
+	// sriovnetworkv1.NeedToUpdateSriov can't see on its own (PF link state, eswitch mode, IB VF GUID)
+	HasOutOfBandDrift(iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt) bool
 	// ConfigSriovDevice configure the request SR-IOV device with the desired configuration
 	ConfigSriovDevice(iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt) error
 	// ConfigSriovInterfaces configure multiple SR-IOV devices with the desired configuration
@@ -111,14 +132,34 @@ type HostManagerInterface interface {
 	// ConfigSriovInterfaces configure virtual functions for virtual environments with the desired configuration
 	ConfigSriovDeviceVirtual(iface *sriovnetworkv1.Interface) error
 
-	// Unbind unbinds a virtual function from is current driver
+	// Unbind unbinds a virtual function from is current driver. Returns ErrNoDriverBound, not
+	// nil, if the device had no driver bound to begin with.
 	Unbind(string) error
 	// BindDpdkDriver binds the virtual function to a DPDK driver
 	BindDpdkDriver(string, string) error
-	// BindDefaultDriver binds the virtual function to is default driver
+	// BindDefaultDriver binds the virtual function to is default driver, automatically retrying
+	// via RebindVfToDefaultDriver if the netdev doesn't come up within vfIsReadyTimeout
 	BindDefaultDriver(string) error
 	// HasDriver returns try if the virtual function is bind to a driver
 	HasDriver(string) (bool, string)
+	// BindDriverByBusAndDevice binds device to driver on the given sysfs bus (consts.BusPci,
+	// consts.BusVdpa, ...), the bus-agnostic primitive Unbind/BindDpdkDriver/BindDefaultDriver build on
+	BindDriverByBusAndDevice(bus, device, driver string) error
+	// UnbindDriverByBusAndDevice unbinds device from its current driver on the given sysfs bus
+	UnbindDriverByBusAndDevice(bus, device string) error
+	// HasDriverByBusAndDevice returns true if device is bound to a driver on the given sysfs bus
+	HasDriverByBusAndDevice(bus, device string) (bool, string)
+
+	// EnsureVdpaDevice makes sure a vdpa device exists on top of the VF at pciAddr and is bound
+	// to driver ("vhost_vdpa" or "virtio_vdpa"), creating/binding or re-binding it as needed
+	EnsureVdpaDevice(pciAddr, driver string) error
+	// RemoveVdpaDevice unbinds and deletes the vdpa device on top of the VF at pciAddr, if any
+	RemoveVdpaDevice(pciAddr string) error
+	// GetVdpaDeviceName resolves the vdpa bus name for the VF at pciAddr, or "" if none exists
+	GetVdpaDeviceName(pciAddr string) (string, error)
+	// HasUserspaceDriver returns true if the virtual function is currently bound to one of
+	// vars.DpdkDrivers, centralizing the "HasDriver + is it a DPDK driver" check
+	HasUserspaceDriver(string) bool
 	// RebindVfToDefaultDriver rebinds the virtual function to is default driver
 	RebindVfToDefaultDriver(string) error
 	// UnbindDriverIfNeeded unbinds the virtual function from a driver if needed
@@ -126,18 +167,44 @@ type HostManagerInterface interface {
 
 	// WriteSwitchdevConfFile writes the needed switchdev configuration files for HW offload support
 	WriteSwitchdevConfFile(*sriovnetworkv1.SriovNetworkNodeState, map[string]bool) (bool, error)
-	// PrepareNMUdevRule creates the needed udev rules to disable NetworkManager from
-	// our managed SR-IOV virtual functions
-	PrepareNMUdevRule([]string) error
-	// AddUdevRule adds a specific udev rule to the system
-	AddUdevRule(string) error
-	// RemoveUdevRule removes a udev rule from the system
+	// PrepareNMUdevRule performs whatever one-time setup the detected NetworkManagerBackend
+	// (NetworkManager, systemd-networkd or none, see network_manager_backend.go) needs to keep its
+	// hands off our managed SR-IOV virtual functions, and prunes stale per-PF state left behind for
+	// PF PCI addresses that are no longer in managedPciAddresses, e.g. because they moved to
+	// ExternallyManaged
+	PrepareNMUdevRule(supportedVfIds []string, managedPciAddresses []string) error
+	// AddUdevRule marks the PF as unmanaged by the detected NetworkManagerBackend, unless
+	// externallyManaged is true, in which case it clears any existing rule for the PF instead
+	AddUdevRule(pfPciAddress string, externallyManaged bool) error
+	// RemoveUdevRule returns the PF to the detected NetworkManagerBackend's management
 	RemoveUdevRule(string) error
+	// AddVfRepresentorUdevRule adds a udev rule that renames a switchdev PF's VF representor
+	// netdevs to a predictable name based on the PF's netdev name
+	AddVfRepresentorUdevRule(pfPciAddress, pfName, switchID, pfIndex string) error
+	// RemoveVfRepresentorUdevRule removes the VF representor udev rule added by
+	// AddVfRepresentorUdevRule for the given PF
+	RemoveVfRepresentorUdevRule(pfPciAddress string) error
+	// WriteVfConfigUdevRules writes the udev rules that re-apply iface's VFs' administrative MAC,
+	// VLAN, trust, spoofchk and link-state whenever they (re)appear, e.g. after a PF re-bind or a
+	// node reboot
+	WriteVfConfigUdevRules(iface *sriovnetworkv1.Interface) error
+	// RemoveVfConfigUdevRules removes the udev rules added by WriteVfConfigUdevRules for the given PF
+	RemoveVfConfigUdevRules(pfPciAddress string) error
 
 	// GetCurrentKernelArgs reads the /proc/cmdline to check the current kernel arguments
 	GetCurrentKernelArgs() (string, error)
 	// IsKernelArgsSet check is the requested kernel arguments are set
 	IsKernelArgsSet(string, string) bool
+	// SetDesiredKernelArgs persists the set of kernel arguments the node is expected to carry,
+	// so that VerifyKernelArgs can keep checking for them across config-daemon restarts
+	SetDesiredKernelArgs(args map[string]bool) error
+	// VerifyKernelArgs compares the persisted desired kernel arguments against the current
+	// /proc/cmdline and returns the ones that are still missing, e.g. because a MachineConfig or
+	// GRUB update was supposed to add them but a reboot hasn't actually picked them up yet
+	VerifyKernelArgs() (missing []string, err error)
+	// GetCPUVendor identifies the host's CPU vendor (Intel, AMD or ARM), so callers can pick the
+	// IOMMU kernel argument that will actually take effect on this hardware
+	GetCPUVendor() (string, error)
 
 	// IsServiceExist checks if the requested systemd service exist on the system
 	IsServiceExist(string) (bool, error)
@@ -145,6 +212,12 @@ type HostManagerInterface interface {
 	IsServiceEnabled(string) (bool, error)
 	// ReadService reads a systemd servers and return it as a struct
 	ReadService(string) (*Service, error)
+	// ReadServiceWithDropIns reads a systemd service together with its drop-in overrides
+	ReadServiceWithDropIns(string) (*Service, error)
+	// EnsureDropIn writes (or overwrites) a single systemd drop-in file for a service
+	EnsureDropIn(service *Service, dropIn *DropIn) error
+	// RemoveDropIn removes a named systemd drop-in file for a service
+	RemoveDropIn(service *Service, dropInName string) error
 	// EnableService enables a systemd server on the host
 	EnableService(service *Service) error
 	// ReadServiceManifestFile reads the systemd manifest for a specific service
@@ -170,32 +243,35 @@ type HostManagerInterface interface {
 	IsUbuntuSystem() (bool, error)
 	// IsCoreOS returns true if the system is a CoreOS or RHCOS base
 	IsCoreOS() (bool, error)
-	// RdmaIsLoaded returns try if RDMA kernel modules are loaded
-	RdmaIsLoaded() (bool, error)
-	// EnableRDMA enable RDMA on the system
-	EnableRDMA(string, string, string) (bool, error)
-	// InstallRDMA install RDMA packages on the system
-	InstallRDMA(string) error
-	// TriggerUdevEvent triggers a udev event
-	TriggerUdevEvent() error
-	// ReloadDriver reloads a requested driver
-	ReloadDriver(string) error
-	// EnableRDMAOnRHELMachine enable RDMA on a RHEL base system
-	EnableRDMAOnRHELMachine() (bool, error)
 	// GetOSPrettyName returns OS name
 	GetOSPrettyName() (string, error)
 }
 
 type hostManager struct {
-	utilsHelper utils.CmdInterface
+	utilsHelper   utils.CmdInterface
+	osBackend     OSBackend
+	osBackendErr  error
+	kernelModules KernelModuleAccessor
 }
 
 func NewHostManager(utilsInterface utils.CmdInterface) HostManagerInterface {
+	osBackend, err := detectOSBackend()
+	if err != nil {
+		log.Log.Error(err, "NewHostManager(): failed to detect host OS backend")
+	}
 	return &hostManager{
-		utilsHelper: utilsInterface,
+		utilsHelper:   utilsInterface,
+		osBackend:     osBackend,
+		osBackendErr:  err,
+		kernelModules: newFsKernelModuleAccessor(),
 	}
 }
 
+// LoadKernelModule loads a kernel module via chroot modprobe. Querying whether it's already
+// loaded goes through KernelModuleAccessor (see kernel_module.go), which reads /proc/modules
+// directly; modprobe itself is kept as-is since actually inserting a module a host doesn't
+// already have the .ko for isn't something this process can do without shelling out to the host's
+// own module tooling.
 func (h *hostManager) LoadKernelModule(name string, args ...string) error {
 	log.Log.Info("LoadKernelModule(): try to load kernel module", "name", name, "args", args)
 	chrootDefinition := utils.GetChrootExtension()
@@ -221,26 +297,16 @@ func (h *hostManager) LoadKernelModule(name string, args ...string) error {
 
 func (h *hostManager) IsKernelModuleLoaded(kernelModuleName string) (bool, error) {
 	log.Log.Info("IsKernelModuleLoaded(): check if kernel module is loaded", "name", kernelModuleName)
-	chrootDefinition := utils.GetChrootExtension()
 
-	stdout, stderr, err := h.utilsHelper.RunCommand("/bin/sh", "-c", fmt.Sprintf("%s lsmod | grep \"^%s\"", chrootDefinition, kernelModuleName))
-	if err != nil && len(stderr) != 0 {
-		log.Log.Error(err, "IsKernelModuleLoaded(): failed to check if kernel module is loaded",
-			"name", kernelModuleName, "stderr", stderr)
-		return false, err
-	}
-	log.Log.V(2).Info("IsKernelModuleLoaded():", "stdout", stdout)
-	if len(stderr) != 0 {
-		log.Log.Error(err, "IsKernelModuleLoaded(): failed to check if kernel module is loaded", "name", kernelModuleName, "stderr", stderr)
-		return false, fmt.Errorf(stderr)
+	loaded, err := h.kernelModules.IsLoaded(kernelModuleName)
+	if err != nil {
+		log.Log.Error(err, "IsKernelModuleLoaded(): failed to check if kernel module is loaded", "name", kernelModuleName)
+		return false, fmt.Errorf("IsKernelModuleLoaded(): %w", err)
 	}
-
-	if len(stdout) != 0 {
+	if loaded {
 		log.Log.Info("IsKernelModuleLoaded(): kernel module already loaded", "name", kernelModuleName)
-		return true, nil
 	}
-
-	return false, nil
+	return loaded, nil
 }
 
 func (h *hostManager) TryEnableTun() {
@@ -255,331 +321,109 @@ func (h *hostManager) TryEnableVhostNet() {
 	}
 }
 
-func (h *hostManager) TryEnableRdma() (bool, error) {
-	log.Log.V(2).Info("tryEnableRdma()")
-	chrootDefinition := utils.GetChrootExtension()
-
-	// check if the driver is already loaded in to the system
-	_, stderr, mlx4Err := h.utilsHelper.RunCommand("/bin/sh", "-c", fmt.Sprintf("grep --quiet 'mlx4_en' <(%s lsmod)", chrootDefinition))
-	if mlx4Err != nil && len(stderr) != 0 {
-		log.Log.Error(mlx4Err, "tryEnableRdma(): failed to check for kernel module 'mlx4_en'", "stderr", stderr)
-		return false, fmt.Errorf(stderr)
-	}
-
-	_, stderr, mlx5Err := h.utilsHelper.RunCommand("/bin/sh", "-c", fmt.Sprintf("grep --quiet 'mlx5_core' <(%s lsmod)", chrootDefinition))
-	if mlx5Err != nil && len(stderr) != 0 {
-		log.Log.Error(mlx5Err, "tryEnableRdma(): failed to check for kernel module 'mlx5_core'", "stderr", stderr)
-		return false, fmt.Errorf(stderr)
-	}
-
-	if mlx4Err != nil && mlx5Err != nil {
-		log.Log.Error(nil, "tryEnableRdma(): no RDMA capable devices")
-		return false, nil
-	}
-
-	isRhelSystem, err := h.IsRHELSystem()
-	if err != nil {
-		log.Log.Error(err, "tryEnableRdma(): failed to check if the machine is base on RHEL")
-		return false, err
-	}
-
-	// RHEL check
-	if isRhelSystem {
-		return h.EnableRDMAOnRHELMachine()
-	}
-
-	isUbuntuSystem, err := h.IsUbuntuSystem()
-	if err != nil {
-		log.Log.Error(err, "tryEnableRdma(): failed to check if the machine is base on Ubuntu")
-		return false, err
-	}
-
-	if isUbuntuSystem {
-		return h.EnableRDMAOnUbuntuMachine()
-	}
-
-	osName, err := h.GetOSPrettyName()
-	if err != nil {
-		log.Log.Error(err, "tryEnableRdma(): failed to check OS name")
-		return false, err
-	}
-
-	log.Log.Error(nil, "tryEnableRdma(): Unsupported OS", "name", osName)
-	return false, fmt.Errorf("unable to load RDMA unsupported OS: %s", osName)
-}
+// CheckRDMAEnabled reports whether the ib_core/rdma_* RDMA kernel modules are loaded. It replaces
+// the old TryEnableRdma chain, which tried to yum/apt-get install rdma-core and reload
+// mlx4_en/mlx5_core: that's broken on RHEL 8/9, never ran on Ubuntu, and isn't appropriate on an
+// immutable RHCOS image in the first place. Getting rdma-core installed is the admin's job, via a
+// MachineConfig or a base image that ships it.
+func (h *hostManager) CheckRDMAEnabled() (bool, error) {
+	log.Log.V(2).Info("CheckRDMAEnabled()")
 
-func (h *hostManager) EnableRDMAOnRHELMachine() (bool, error) {
-	log.Log.Info("EnableRDMAOnRHELMachine()")
-	isCoreOsSystem, err := h.IsCoreOS()
-	if err != nil {
-		log.Log.Error(err, "EnableRDMAOnRHELMachine(): failed to check if the machine runs CoreOS")
-		return false, err
-	}
-
-	// CoreOS check
-	if isCoreOsSystem {
-		isRDMALoaded, err := h.RdmaIsLoaded()
-		if err != nil {
-			log.Log.Error(err, "EnableRDMAOnRHELMachine(): failed to check if RDMA kernel modules are loaded")
-			return false, err
-		}
-
-		return isRDMALoaded, nil
-	}
-
-	// RHEL
-	log.Log.Info("EnableRDMAOnRHELMachine(): enabling RDMA on RHEL machine")
-	isRDMAEnable, err := h.EnableRDMA(rhelRDMAConditionFile, rhelRDMAServiceName, rhelPackageManager)
-	if err != nil {
-		log.Log.Error(err, "EnableRDMAOnRHELMachine(): failed to enable RDMA on RHEL machine")
-		return false, err
-	}
-
-	// check if we need to install rdma-core package
-	if isRDMAEnable {
-		isRDMALoaded, err := h.RdmaIsLoaded()
+	for _, prefix := range []string{"ib", "rdma"} {
+		loaded, err := h.kernelModules.IsLoadedWithPrefix(prefix)
 		if err != nil {
-			log.Log.Error(err, "EnableRDMAOnRHELMachine(): failed to check if RDMA kernel modules are loaded")
-			return false, err
+			log.Log.Error(err, "CheckRDMAEnabled(): fail to check if ib and rdma kernel modules are loaded")
+			return false, fmt.Errorf("CheckRDMAEnabled(): %w", err)
 		}
-
-		// if ib kernel module is not loaded trigger a loading
-		if isRDMALoaded {
-			err = h.TriggerUdevEvent()
-			if err != nil {
-				log.Log.Error(err, "EnableRDMAOnRHELMachine() failed to trigger udev event")
-				return false, err
-			}
+		if loaded {
+			return true, nil
 		}
 	}
 
-	return true, nil
-}
-
-func (h *hostManager) EnableRDMAOnUbuntuMachine() (bool, error) {
-	log.Log.Info("EnableRDMAOnUbuntuMachine(): enabling RDMA on RHEL machine")
-	isRDMAEnable, err := h.EnableRDMA(ubuntuRDMAConditionFile, ubuntuRDMAServiceName, ubuntuPackageManager)
-	if err != nil {
-		log.Log.Error(err, "EnableRDMAOnUbuntuMachine(): failed to enable RDMA on Ubuntu machine")
-		return false, err
-	}
-
-	// check if we need to install rdma-core package
-	if isRDMAEnable {
-		isRDMALoaded, err := h.RdmaIsLoaded()
-		if err != nil {
-			log.Log.Error(err, "EnableRDMAOnUbuntuMachine(): failed to check if RDMA kernel modules are loaded")
-			return false, err
-		}
-
-		// if ib kernel module is not loaded trigger a loading
-		if isRDMALoaded {
-			err = h.TriggerUdevEvent()
-			if err != nil {
-				log.Log.Error(err, "EnableRDMAOnUbuntuMachine() failed to trigger udev event")
-				return false, err
-			}
-		}
-	}
-
-	return true, nil
-}
-
-func (h *hostManager) IsRHELSystem() (bool, error) {
-	log.Log.Info("IsRHELSystem(): checking for RHEL machine")
-	path := redhatReleaseFile
-	if !vars.UsingSystemdMode {
-		path = pathlib.Join(hostPathFromDaemon, path)
-	}
-	if _, err := os.Stat(path); err != nil {
-		if os.IsNotExist(err) {
-			log.Log.V(2).Info("IsRHELSystem() not a RHEL machine")
-			return false, nil
-		}
-
-		log.Log.Error(err, "IsRHELSystem() failed to check for os release file", "path", path)
-		return false, err
-	}
-
-	return true, nil
-}
-
-func (h *hostManager) IsCoreOS() (bool, error) {
-	log.Log.Info("IsCoreOS(): checking for CoreOS machine")
-	path := redhatReleaseFile
-	if !vars.UsingSystemdMode {
-		path = pathlib.Join(hostPathFromDaemon, path)
-	}
-
-	data, err := os.ReadFile(path)
-	if err != nil {
-		log.Log.Error(err, "IsCoreOS(): failed to read RHEL release file on path", "path", path)
-		return false, err
-	}
-
-	if strings.Contains(string(data), "CoreOS") {
-		return true, nil
-	}
-
 	return false, nil
 }
 
-func (h *hostManager) IsUbuntuSystem() (bool, error) {
-	log.Log.Info("IsUbuntuSystem(): checking for Ubuntu machine")
-	path := genericOSReleaseFile
-	if !vars.UsingSystemdMode {
-		path = pathlib.Join(hostPathFromDaemon, path)
-	}
+// IsKernelModuleAvailable reports whether the named kernel module is already loaded or exists
+// under /lib/modules/$(uname -r).
+func (h *hostManager) IsKernelModuleAvailable(name string) (bool, error) {
+	log.Log.V(2).Info("IsKernelModuleAvailable()", "name", name)
 
-	if _, err := os.Stat(path); err != nil {
-		if os.IsNotExist(err) {
-			log.Log.Error(nil, "IsUbuntuSystem() os-release on path doesn't exist", "path", path)
-			return false, err
-		}
-
-		log.Log.Error(err, "IsUbuntuSystem() failed to check for os release file", "path", path)
-		return false, err
-	}
-
-	stdout, stderr, err := h.utilsHelper.RunCommand("/bin/sh", "-c", fmt.Sprintf("grep -i --quiet 'ubuntu' %s", path))
-	if err != nil && len(stderr) != 0 {
-		log.Log.Error(err, "IsUbuntuSystem(): failed to check for ubuntu operating system name in os-releasae file", "stderr", stderr)
-		return false, fmt.Errorf(stderr)
-	}
-
-	if len(stdout) > 0 {
-		return true, nil
+	available, err := h.kernelModules.IsAvailable(name)
+	if err != nil {
+		log.Log.Error(err, "IsKernelModuleAvailable(): fail to check if kernel module is available", "name", name)
+		return false, fmt.Errorf("IsKernelModuleAvailable(): %w", err)
 	}
-
-	return false, nil
+	return available, nil
 }
 
-func (h *hostManager) RdmaIsLoaded() (bool, error) {
-	log.Log.V(2).Info("RdmaIsLoaded()")
-	chrootDefinition := utils.GetChrootExtension()
+// infinibandClassPath and ibvDevicesBinary are used by CheckRDMAUserlandAvailable to detect
+// rdma-core userland without shelling out to anything more invasive than `exec.LookPath`.
+const infinibandClassPath = "/sys/class/infiniband"
 
-	// check if the driver is already loaded in to the system
-	_, stderr, err := h.utilsHelper.RunCommand("/bin/sh", "-c", fmt.Sprintf("grep --quiet '\\(^ib\\|^rdma\\)' <(%s lsmod)", chrootDefinition))
-	if err != nil && len(stderr) != 0 {
-		log.Log.Error(err, "RdmaIsLoaded(): fail to check if ib and rdma kernel modules are loaded", "stderr", stderr)
-		return false, fmt.Errorf(stderr)
-	}
-
-	if err != nil {
-		return false, nil
-	}
-
-	return true, nil
-}
+const ibvDevicesBinary = "ibv_devices"
 
-func (h *hostManager) EnableRDMA(conditionFilePath, serviceName, packageManager string) (bool, error) {
-	path := conditionFilePath
-	if !vars.UsingSystemdMode {
-		path = pathlib.Join(hostPathFromDaemon, path)
-	}
-	log.Log.Info("EnableRDMA(): checking for service file", "path", path)
+// CheckRDMAUserlandAvailable reports whether rdma-core userland is present: /sys/class/infiniband
+// is exposed by the kernel and ibv_devices is reachable on PATH.
+func (h *hostManager) CheckRDMAUserlandAvailable() (bool, error) {
+	log.Log.V(2).Info("CheckRDMAUserlandAvailable()")
 
+	path := filepath.Join(utils.GetHostExtension(), infinibandClassPath)
 	if _, err := os.Stat(path); err != nil {
 		if os.IsNotExist(err) {
-			log.Log.V(2).Info("EnableRDMA(): RDMA server doesn't exist")
-			err = h.InstallRDMA(packageManager)
-			if err != nil {
-				log.Log.Error(err, "EnableRDMA() failed to install RDMA package")
-				return false, err
-			}
-
-			err = h.TriggerUdevEvent()
-			if err != nil {
-				log.Log.Error(err, "EnableRDMA() failed to trigger udev event")
-				return false, err
-			}
-
 			return false, nil
 		}
-
-		log.Log.Error(err, "EnableRDMA() failed to check for os release file", "path", path)
-		return false, err
+		return false, fmt.Errorf("CheckRDMAUserlandAvailable(): failed to stat %s: %w", path, err)
 	}
 
-	log.Log.Info("EnableRDMA(): service installed", "name", serviceName)
+	if _, err := exec.LookPath(ibvDevicesBinary); err != nil {
+		return false, nil
+	}
 	return true, nil
 }
 
-func (h *hostManager) InstallRDMA(packageManager string) error {
-	log.Log.Info("InstallRDMA(): installing RDMA")
-	chrootDefinition := utils.GetChrootExtension()
-
-	stdout, stderr, err := h.utilsHelper.RunCommand("/bin/sh", "-c", fmt.Sprintf("%s %s install -y rdma-core", chrootDefinition, packageManager))
-	if err != nil && len(stderr) != 0 {
-		log.Log.Error(err, "InstallRDMA(): failed to install RDMA package", "stdout", stdout, "stderr", stderr)
-		return err
+// IsRHELSystem returns true for RHEL and RHEL-derived distros (CentOS, Fedora, RHCOS, ...),
+// delegating to the OSBackend detected once at NewHostManager construction time.
+func (h *hostManager) IsRHELSystem() (bool, error) {
+	if h.osBackendErr != nil {
+		return false, h.osBackendErr
 	}
-
-	return nil
+	return h.osBackend.IsRHEL(), nil
 }
 
-func (h *hostManager) TriggerUdevEvent() error {
-	log.Log.Info("TriggerUdevEvent(): installing RDMA")
-
-	err := h.ReloadDriver("mlx4_en")
-	if err != nil {
-		return err
-	}
-
-	err = h.ReloadDriver("mlx5_core")
-	if err != nil {
-		return err
+// IsCoreOS returns true specifically for CoreOS/RHCOS.
+func (h *hostManager) IsCoreOS() (bool, error) {
+	if h.osBackendErr != nil {
+		return false, h.osBackendErr
 	}
-
-	return nil
+	return h.osBackend.IsCoreOS(), nil
 }
 
-func (h *hostManager) ReloadDriver(driverName string) error {
-	log.Log.Info("ReloadDriver(): reload driver", "name", driverName)
-	chrootDefinition := utils.GetChrootExtension()
-
-	_, stderr, err := h.utilsHelper.RunCommand("/bin/sh", "-c", fmt.Sprintf("%s modprobe -r %s && %s modprobe %s", chrootDefinition, driverName, chrootDefinition, driverName))
-	if err != nil && len(stderr) != 0 {
-		log.Log.Error(err, "InstallRDMA(): failed to reload kernel module",
-			"name", driverName, "stderr", stderr)
-		return err
+// IsUbuntuSystem returns true for Ubuntu/Debian-derived distros.
+func (h *hostManager) IsUbuntuSystem() (bool, error) {
+	if h.osBackendErr != nil {
+		return false, h.osBackendErr
 	}
-
-	return nil
+	return h.osBackend.IsUbuntu(), nil
 }
 
+// GetOSPrettyName returns the distro's human-readable name (os-release PRETTY_NAME).
 func (h *hostManager) GetOSPrettyName() (string, error) {
-	path := genericOSReleaseFile
-	if !vars.UsingSystemdMode {
-		path = pathlib.Join(hostPathFromDaemon, path)
-	}
-
-	log.Log.Info("GetOSPrettyName(): getting os name from os-release file")
-
-	stdout, stderr, err := h.utilsHelper.RunCommand("/bin/sh", "-c", fmt.Sprintf("cat %s | grep PRETTY_NAME | cut -c 13-", path))
-	if err != nil && len(stderr) != 0 {
-		log.Log.Error(err, "IsUbuntuSystem(): failed to check for ubuntu operating system name in os-releasae file", "stderr", stderr)
-		return "", fmt.Errorf(stderr)
+	if h.osBackendErr != nil {
+		return "", h.osBackendErr
 	}
-
-	if len(stdout) > 0 {
-		return stdout, nil
-	}
-
-	return "", fmt.Errorf("failed to find pretty operating system name")
+	return h.osBackend.PrettyName(), nil
 }
 
 // IsKernelLockdownMode returns true when kernel lockdown mode is enabled
 // TODO: change this to return error
 func (h *hostManager) IsKernelLockdownMode() bool {
-	path := utils.GetHostExtension()
-	path = filepath.Join(path, "/sys/kernel/security/lockdown")
+	path := filepath.Join(utils.GetHostExtension(), "/sys/kernel/security/lockdown")
 
-	stdout, stderr, err := h.utilsHelper.RunCommand("/bin/sh", "-c", "cat", path)
-	log.Log.V(2).Info("IsKernelLockdownMode()", "output", stdout, "error", err)
+	data, err := os.ReadFile(path)
+	log.Log.V(2).Info("IsKernelLockdownMode()", "output", string(data), "error", err)
 	if err != nil {
-		log.Log.Error(err, "IsKernelLockdownMode(): failed to check for lockdown file", "stderr", stderr)
+		log.Log.Error(err, "IsKernelLockdownMode(): failed to check for lockdown file")
 		return false
 	}
-	return strings.Contains(stdout, "[integrity]") || strings.Contains(stdout, "[confidentiality]")
+	return strings.Contains(string(data), "[integrity]") || strings.Contains(string(data), "[confidentiality]")
 }
@@ -0,0 +1,9 @@
+// Package busdriver holds sentinel errors for sysfs bus driver bind/unbind state that are shared
+// between pkg/host and pkg/host/vdpa. It exists as its own leaf package so pkg/host/vdpa (which
+// pkg/host already depends on) can check these errors without importing pkg/host back.
+package busdriver
+
+import "errors"
+
+// ErrNoDriverBound indicates a bus device (PCI, vdpa, ...) currently has no driver bound.
+var ErrNoDriverBound = errors.New("device has no driver bound")
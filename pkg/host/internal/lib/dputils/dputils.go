@@ -0,0 +1,79 @@
+// Package dputils wraps github.com/k8snetworkplumbingwg/sriov-network-device-plugin/pkg/utils
+// behind a small interface, so driver-binding code in pkg/host can be unit-tested against a fake
+// implementation instead of being pinned to the real host's sysfs layout.
+package dputils
+
+import (
+	dputils "github.com/k8snetworkplumbingwg/sriov-network-device-plugin/pkg/utils"
+)
+
+//go:generate ../../../../../bin/mockgen -destination mock/mock_dputils.go -source dputils.go
+
+// DPUtilsLib is the subset of sriov-network-device-plugin's utils package that pkg/host depends
+// on for driver and VF bookkeeping.
+type DPUtilsLib interface {
+	// GetDriverName returns the name of the kernel driver bound to pciAddr
+	GetDriverName(pciAddr string) (string, error)
+	// GetNetNames returns the netdev names exposed by pciAddr, if any
+	GetNetNames(pciAddr string) ([]string, error)
+	// GetVFID returns the VF index of pciAddr within its PF
+	GetVFID(pciAddr string) (int, error)
+	// GetVFList returns the PCI addresses of the VFs belonging to pfAddr
+	GetVFList(pfAddr string) ([]string, error)
+	// IsSriovVF returns true if pciAddr is a virtual function
+	IsSriovVF(pciAddr string) bool
+	// IsSriovPF returns true if pciAddr is an SR-IOV capable physical function
+	IsSriovPF(pciAddr string) bool
+	// SriovConfigured returns true if pciAddr currently has VFs configured
+	SriovConfigured(pciAddr string) bool
+	// GetVFconfigured returns the number of VFs currently configured on pfAddr
+	GetVFconfigured(pfAddr string) int
+	// GetSriovVFcapacity returns the maximum number of VFs pfAddr supports
+	GetSriovVFcapacity(pfAddr string) int
+}
+
+// New returns the real DPUtilsLib implementation, backed directly by the
+// sriov-network-device-plugin utils package.
+func New() DPUtilsLib {
+	return &libWrapper{}
+}
+
+// libWrapper is a thin pass-through to the real dputils package, existing only so DPUtilsLib can
+// be swapped out for a fake in tests.
+type libWrapper struct{}
+
+func (w *libWrapper) GetDriverName(pciAddr string) (string, error) {
+	return dputils.GetDriverName(pciAddr)
+}
+
+func (w *libWrapper) GetNetNames(pciAddr string) ([]string, error) {
+	return dputils.GetNetNames(pciAddr)
+}
+
+func (w *libWrapper) GetVFID(pciAddr string) (int, error) {
+	return dputils.GetVFID(pciAddr)
+}
+
+func (w *libWrapper) GetVFList(pfAddr string) ([]string, error) {
+	return dputils.GetVFList(pfAddr)
+}
+
+func (w *libWrapper) IsSriovVF(pciAddr string) bool {
+	return dputils.IsSriovVF(pciAddr)
+}
+
+func (w *libWrapper) IsSriovPF(pciAddr string) bool {
+	return dputils.IsSriovPF(pciAddr)
+}
+
+func (w *libWrapper) SriovConfigured(pciAddr string) bool {
+	return dputils.SriovConfigured(pciAddr)
+}
+
+func (w *libWrapper) GetVFconfigured(pfAddr string) int {
+	return dputils.GetVFconfigured(pfAddr)
+}
+
+func (w *libWrapper) GetSriovVFcapacity(pfAddr string) int {
+	return dputils.GetSriovVFcapacity(pfAddr)
+}
@@ -1,15 +1,30 @@
 package host
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
 )
 
+// desiredKargsFile is where the operator's own desired kernel-argument set is persisted on the
+// host, separately from /proc/cmdline, so it survives a config-daemon restart and still lets
+// VerifyKernelArgs notice an arg that a MachineConfig/GRUB update was supposed to add but didn't.
+const desiredKargsFile = "/etc/sriov-operator/desired-kargs.json"
+
+func desiredKargsPath() string {
+	path := desiredKargsFile
+	if !vars.UsingSystemdMode {
+		path = filepath.Join("/host", path)
+	}
+	return filepath.Join(vars.FilesystemRoot, path)
+}
+
 // GetCurrentKernelArgs This retrieves the kernel cmd line arguments
 func (h *HostManager) GetCurrentKernelArgs() (string, error) {
 	path := consts.ProcKernelCmdLine
@@ -36,3 +51,120 @@ func (h *HostManager) IsKernelArgsSet(cmdLine string, karg string) bool {
 	}
 	return false
 }
+
+// SetDesiredKernelArgs persists the full set of kernel arguments the node is expected to carry to
+// desiredKargsFile, overwriting whatever was recorded before. Callers own the bool values (e.g.
+// "already attempted to set it"); VerifyKernelArgs only cares about the keys.
+func (h *HostManager) SetDesiredKernelArgs(args map[string]bool) error {
+	path := desiredKargsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("SetDesiredKernelArgs(): failed to create %s: %v", filepath.Dir(path), err)
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("SetDesiredKernelArgs(): failed to marshal desired kernel args: %v", err)
+	}
+
+	if err := atomicWriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("SetDesiredKernelArgs(): failed to write %s: %v", path, err)
+	}
+	return nil
+}
+
+// VerifyKernelArgs compares the kernel arguments persisted by SetDesiredKernelArgs against the
+// current /proc/cmdline and returns the ones that are still missing. A nil desiredKargsFile (no
+// desired kernel args ever recorded) is not an error: it just means nothing is missing.
+func (h *HostManager) VerifyKernelArgs() ([]string, error) {
+	path := desiredKargsPath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("VerifyKernelArgs(): failed to read %s: %v", path, err)
+	}
+
+	desired := map[string]bool{}
+	if err := json.Unmarshal(data, &desired); err != nil {
+		return nil, fmt.Errorf("VerifyKernelArgs(): failed to parse %s: %v", path, err)
+	}
+
+	cmdLine, err := h.GetCurrentKernelArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for karg := range desired {
+		if !h.IsKernelArgsSet(cmdLine, karg) {
+			missing = append(missing, karg)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// CPU vendor identifiers returned by GetCPUVendor. CPUVendorIntel and CPUVendorAMD match
+// /proc/cpuinfo's vendor_id field verbatim; ARM doesn't set vendor_id at all, so CPUVendorARM is
+// reported from a devicetree/DMI check instead.
+const (
+	CPUVendorIntel = "GenuineIntel"
+	CPUVendorAMD   = "AuthenticAMD"
+	CPUVendorARM   = "ARM"
+)
+
+const (
+	procCPUInfoFile             = "/proc/cpuinfo"
+	armDeviceTreeCompatibleFile = "/sys/firmware/devicetree/base/compatible"
+	dmiModaliasFile             = "/sys/devices/virtual/dmi/id/modalias"
+)
+
+// GetCPUVendor identifies the host's CPU vendor so kernel-arg selection (e.g. Intel's
+// intel_iommu=on vs. AMD's amd_iommu=on) can pick the flag that will actually take effect.
+func (h *HostManager) GetCPUVendor() (string, error) {
+	path := hostFilePath(procCPUInfoFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("GetCPUVendor(): error reading %s: %v", path, err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(key) != "vendor_id" {
+			continue
+		}
+		return strings.TrimSpace(value), nil
+	}
+
+	// x86 always reports a vendor_id; ARM doesn't, so its absence here means we're on ARM only if
+	// a devicetree or DMI check agrees.
+	if h.isARMPlatform() {
+		return CPUVendorARM, nil
+	}
+
+	return "", fmt.Errorf("GetCPUVendor(): no vendor_id found in %s", path)
+}
+
+// isARMPlatform reports whether the host looks like an ARM platform: either it exposes a
+// devicetree (x86 hosts never do), or its DMI modalias mentions an arm-smmu IOMMU.
+func (h *HostManager) isARMPlatform() bool {
+	if _, err := os.Stat(hostFilePath(armDeviceTreeCompatibleFile)); err == nil {
+		return true
+	}
+
+	data, err := os.ReadFile(hostFilePath(dmiModaliasFile))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(data), "arm-smmu")
+}
+
+// hostFilePath resolves a path under /proc or /sys to its location on the (possibly chrooted)
+// host filesystem, the same way GetCurrentKernelArgs resolves /proc/cmdline.
+func hostFilePath(path string) string {
+	if !vars.UsingSystemdMode {
+		path = filepath.Join("/host", path)
+	}
+	return filepath.Join(vars.FilesystemRoot, path)
+}
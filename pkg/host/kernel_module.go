@@ -0,0 +1,142 @@
+package host
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+// KernelModuleAccessor reports which kernel modules are currently loaded by reading /proc/modules
+// directly off the (possibly chrooted) host filesystem, instead of shelling out through chroot to
+// `lsmod | grep`. That makes the result a typed error instead of an exit code conflated with "not
+// found", and lets tests swap in an in-memory afero.Fs instead of mocking exec.
+type KernelModuleAccessor interface {
+	// IsLoaded reports whether the named kernel module is currently loaded
+	IsLoaded(name string) (bool, error)
+	// IsLoadedWithPrefix reports whether any currently loaded module's name starts with prefix,
+	// e.g. "ib" or "rdma"
+	IsLoadedWithPrefix(prefix string) (bool, error)
+	// IsAvailable reports whether the named kernel module is already loaded (/sys/module/<name>)
+	// or exists under /lib/modules/$(uname -r)
+	IsAvailable(name string) (bool, error)
+}
+
+type fsKernelModuleAccessor struct {
+	fs afero.Fs
+}
+
+// newFsKernelModuleAccessor returns a KernelModuleAccessor backed by the real OS filesystem
+func newFsKernelModuleAccessor() KernelModuleAccessor {
+	return &fsKernelModuleAccessor{fs: afero.NewOsFs()}
+}
+
+func (a *fsKernelModuleAccessor) procModulesPath() string {
+	return filepath.Join(utils.GetHostExtension(), "/proc/modules")
+}
+
+// loadedModuleNames parses the first column of every line of /proc/modules, which is
+// "name size refcount deps state addr" per proc(5)
+func (a *fsKernelModuleAccessor) loadedModuleNames() ([]string, error) {
+	path := a.procModulesPath()
+	data, err := afero.ReadFile(a.fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var names []string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		names = append(names, fields[0])
+	}
+	return names, nil
+}
+
+func (a *fsKernelModuleAccessor) IsLoaded(name string) (bool, error) {
+	names, err := a.loadedModuleNames()
+	if err != nil {
+		return false, err
+	}
+	for _, loaded := range names {
+		if loaded == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (a *fsKernelModuleAccessor) IsLoadedWithPrefix(prefix string) (bool, error) {
+	names, err := a.loadedModuleNames()
+	if err != nil {
+		return false, err
+	}
+	for _, loaded := range names {
+		if strings.HasPrefix(loaded, prefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (a *fsKernelModuleAccessor) osReleasePath() string {
+	return filepath.Join(utils.GetHostExtension(), "/proc/sys/kernel/osrelease")
+}
+
+// kernelRelease reads the running kernel's release string (e.g. "5.14.0-284.el9.x86_64"), the
+// same value `uname -r` would print, without shelling out through chroot.
+func (a *fsKernelModuleAccessor) kernelRelease() (string, error) {
+	path := a.osReleasePath()
+	data, err := afero.ReadFile(a.fs, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (a *fsKernelModuleAccessor) sysModulePath(name string) string {
+	return filepath.Join(utils.GetHostExtension(), "/sys/module", name)
+}
+
+// IsAvailable reports whether the named kernel module can be used right now: either it's already
+// loaded (/sys/module/<name> exists), or name.ko (in any of the usual kernel-supplied
+// compressions) exists anywhere under /lib/modules/$(uname -r) for modprobe to load on demand.
+func (a *fsKernelModuleAccessor) IsAvailable(name string) (bool, error) {
+	if _, err := a.fs.Stat(a.sysModulePath(name)); err == nil {
+		return true, nil
+	}
+
+	release, err := a.kernelRelease()
+	if err != nil {
+		return false, err
+	}
+
+	root := filepath.Join(utils.GetHostExtension(), "/lib/modules", release)
+	found := false
+	err = afero.Walk(a.fs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		base := filepath.Base(path)
+		if base == name+".ko" || strings.HasPrefix(base, name+".ko.") {
+			found = true
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to search %s for %s: %w", root, name, err)
+	}
+	return found, nil
+}
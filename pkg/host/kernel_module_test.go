@@ -0,0 +1,93 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const fakeProcModules = `vfio_pci 16384 0 - Live 0x0000000000000000
+ib_core 425984 2 mlx5_ib,rdma_cm, Live 0x0000000000000000
+rdma_ucm 36864 0 - Live 0x0000000000000000
+vhost_net 24576 0 - Live 0x0000000000000000
+`
+
+func newTestFsKernelModuleAccessor(t *testing.T) (*fsKernelModuleAccessor, afero.Fs) {
+	vars.InChroot = true
+	vars.FilesystemRoot = ""
+	t.Cleanup(func() { vars.InChroot = false })
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/proc/modules", []byte(fakeProcModules), 0644))
+	return &fsKernelModuleAccessor{fs: fs}, fs
+}
+
+func TestFsKernelModuleAccessorIsLoaded(t *testing.T) {
+	a, _ := newTestFsKernelModuleAccessor(t)
+
+	loaded, err := a.IsLoaded("vhost_net")
+	require.NoError(t, err)
+	assert.True(t, loaded)
+
+	loaded, err = a.IsLoaded("vfio_iommu_type1")
+	require.NoError(t, err)
+	assert.False(t, loaded)
+}
+
+func TestFsKernelModuleAccessorIsLoadedWithPrefix(t *testing.T) {
+	a, _ := newTestFsKernelModuleAccessor(t)
+
+	loaded, err := a.IsLoadedWithPrefix("ib")
+	require.NoError(t, err)
+	assert.True(t, loaded)
+
+	loaded, err = a.IsLoadedWithPrefix("rdma")
+	require.NoError(t, err)
+	assert.True(t, loaded)
+
+	loaded, err = a.IsLoadedWithPrefix("mlx5")
+	require.NoError(t, err)
+	assert.False(t, loaded)
+}
+
+func TestFsKernelModuleAccessorMissingProcModules(t *testing.T) {
+	vars.InChroot = true
+	vars.FilesystemRoot = ""
+	t.Cleanup(func() { vars.InChroot = false })
+
+	a := &fsKernelModuleAccessor{fs: afero.NewMemMapFs()}
+	_, err := a.IsLoaded("vhost_net")
+	assert.Error(t, err)
+}
+
+func TestFsKernelModuleAccessorIsAvailableAlreadyLoaded(t *testing.T) {
+	a, fs := newTestFsKernelModuleAccessor(t)
+	require.NoError(t, afero.WriteFile(fs, "/sys/module/vfio_pci/initstate", []byte("live\n"), 0644))
+
+	available, err := a.IsAvailable("vfio_pci")
+	require.NoError(t, err)
+	assert.True(t, available)
+}
+
+func TestFsKernelModuleAccessorIsAvailableOnDiskNotLoaded(t *testing.T) {
+	a, fs := newTestFsKernelModuleAccessor(t)
+	require.NoError(t, afero.WriteFile(fs, "/proc/sys/kernel/osrelease", []byte("5.14.0-284.el9.x86_64\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/lib/modules/5.14.0-284.el9.x86_64/kernel/drivers/vfio/pci/vfio-pci.ko.xz", nil, 0644))
+
+	available, err := a.IsAvailable("vfio-pci")
+	require.NoError(t, err)
+	assert.True(t, available)
+}
+
+func TestFsKernelModuleAccessorIsAvailableMissing(t *testing.T) {
+	a, fs := newTestFsKernelModuleAccessor(t)
+	require.NoError(t, afero.WriteFile(fs, "/proc/sys/kernel/osrelease", []byte("5.14.0-284.el9.x86_64\n"), 0644))
+
+	available, err := a.IsAvailable("vfio_pci")
+	require.NoError(t, err)
+	assert.False(t, available)
+}
@@ -0,0 +1,110 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+// setupKernelArgsTestFS points vars.FilesystemRoot at a scratch directory and writes cmdLine as
+// its fake /proc/cmdline, so SetDesiredKernelArgs/VerifyKernelArgs exercise real file I/O without
+// touching the actual host.
+func setupKernelArgsTestFS(t *testing.T, cmdLine string) *HostManager {
+	vars.FilesystemRoot = t.TempDir()
+	vars.UsingSystemdMode = true
+	t.Cleanup(func() {
+		vars.FilesystemRoot = ""
+		vars.UsingSystemdMode = false
+	})
+
+	cmdLinePath := filepath.Join(vars.FilesystemRoot, consts.ProcKernelCmdLine)
+	require.NoError(t, os.MkdirAll(filepath.Dir(cmdLinePath), 0755))
+	require.NoError(t, os.WriteFile(cmdLinePath, []byte(cmdLine), 0644))
+
+	return &HostManager{}
+}
+
+func TestVerifyKernelArgsNoneDesired(t *testing.T) {
+	h := setupKernelArgsTestFS(t, "BOOT_IMAGE=/vmlinuz root=/dev/sda1")
+
+	missing, err := h.VerifyKernelArgs()
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func TestSetAndVerifyKernelArgsAllMissing(t *testing.T) {
+	h := setupKernelArgsTestFS(t, "BOOT_IMAGE=/vmlinuz root=/dev/sda1")
+
+	require.NoError(t, h.SetDesiredKernelArgs(map[string]bool{
+		"intel_iommu=on": false,
+		"iommu=pt":       false,
+	}))
+
+	missing, err := h.VerifyKernelArgs()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"intel_iommu=on", "iommu=pt"}, missing)
+}
+
+func setupCPUInfoTestFS(t *testing.T, cpuInfo string) *HostManager {
+	vars.FilesystemRoot = t.TempDir()
+	vars.UsingSystemdMode = true
+	t.Cleanup(func() {
+		vars.FilesystemRoot = ""
+		vars.UsingSystemdMode = false
+	})
+
+	if cpuInfo != "" {
+		cpuInfoPath := filepath.Join(vars.FilesystemRoot, procCPUInfoFile)
+		require.NoError(t, os.MkdirAll(filepath.Dir(cpuInfoPath), 0755))
+		require.NoError(t, os.WriteFile(cpuInfoPath, []byte(cpuInfo), 0644))
+	}
+
+	return &HostManager{}
+}
+
+func TestGetCPUVendorIntel(t *testing.T) {
+	h := setupCPUInfoTestFS(t, "processor\t: 0\nvendor_id\t: GenuineIntel\ncpu family\t: 6\n")
+
+	vendor, err := h.GetCPUVendor()
+	require.NoError(t, err)
+	assert.Equal(t, CPUVendorIntel, vendor)
+}
+
+func TestGetCPUVendorAMD(t *testing.T) {
+	h := setupCPUInfoTestFS(t, "processor\t: 0\nvendor_id\t: AuthenticAMD\ncpu family\t: 25\n")
+
+	vendor, err := h.GetCPUVendor()
+	require.NoError(t, err)
+	assert.Equal(t, CPUVendorAMD, vendor)
+}
+
+func TestGetCPUVendorARM(t *testing.T) {
+	h := setupCPUInfoTestFS(t, "processor\t: 0\nBogoMIPS\t: 50.00\nFeatures\t: fp asimd\nCPU implementer\t: 0x41\n")
+
+	compatiblePath := filepath.Join(vars.FilesystemRoot, armDeviceTreeCompatibleFile)
+	require.NoError(t, os.MkdirAll(filepath.Dir(compatiblePath), 0755))
+	require.NoError(t, os.WriteFile(compatiblePath, []byte("arm,juno\x00"), 0644))
+
+	vendor, err := h.GetCPUVendor()
+	require.NoError(t, err)
+	assert.Equal(t, CPUVendorARM, vendor)
+}
+
+func TestVerifyKernelArgsPartiallyApplied(t *testing.T) {
+	h := setupKernelArgsTestFS(t, "BOOT_IMAGE=/vmlinuz root=/dev/sda1 intel_iommu=on")
+
+	require.NoError(t, h.SetDesiredKernelArgs(map[string]bool{
+		"intel_iommu=on": true,
+		"iommu=pt":       false,
+	}))
+
+	missing, err := h.VerifyKernelArgs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"iommu=pt"}, missing)
+}
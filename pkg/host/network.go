@@ -97,6 +97,19 @@ func (h *HostManager) GetNicSriovMode(pciAddress string) (string, error) {
 	return devLink.Attrs.Eswitch.Mode, nil
 }
 
+// SetNicSriovMode sets the devlink eswitch mode for the device, e.g. to fix up a mode that
+// drifted out-of-band from the one recorded in the SriovNetworkNodeState spec.
+func (h *HostManager) SetNicSriovMode(pciAddress, mode string) error {
+	log.Log.V(2).Info("SetNicSriovMode()", "device", pciAddress, "mode", mode)
+
+	devLink, err := netlink.DevLinkGetDeviceByName("pci", pciAddress)
+	if err != nil {
+		return err
+	}
+
+	return netlink.DevLinkSetEswitchMode(devLink, mode)
+}
+
 func (h *HostManager) GetPhysSwitchID(name string) (string, error) {
 	swIDFile := filepath.Join(vars.FilesystemRoot, consts.SysClassNet, name, "phys_switch_id")
 	physSwitchID, err := os.ReadFile(swIDFile)
@@ -130,6 +143,88 @@ func (h *HostManager) IsSwitchdev(name string) bool {
 	return true
 }
 
+// GetVfRepresentor returns the representor netdev name for a given VF index of a switchdev PF.
+// Representors share the PF's phys_switch_id and advertise a phys_port_name of "pf<pfNum>vf<vfIndex>",
+// where pfNum is parsed from the PF's own phys_port_name (vars.PfPhysPortNameRe, "p<pfNum>"). It
+// returns an empty string (and no error) when the PF is not running in switchdev mode.
+func (h *HostManager) GetVfRepresentor(pfName string, vfIndex int) (string, error) {
+	log.Log.V(2).Info("GetVfRepresentor()", "pf", pfName, "vf", vfIndex)
+	pfSwitchID, err := h.GetPhysSwitchID(pfName)
+	if err != nil {
+		return "", err
+	}
+	if pfSwitchID == "" {
+		return "", nil
+	}
+
+	pfPortName, err := h.GetPhysPortName(pfName)
+	if err != nil {
+		return "", err
+	}
+	pfNum := strings.TrimPrefix(vars.PfPhysPortNameRe.FindString(pfPortName), "p")
+	if pfNum == "" {
+		return "", fmt.Errorf("GetVfRepresentor(): PF %s has unexpected phys_port_name %q", pfName, pfPortName)
+	}
+
+	wantPortName := fmt.Sprintf("pf%svf%d", pfNum, vfIndex)
+
+	netClassDir := filepath.Join(vars.FilesystemRoot, consts.SysClassNet)
+	entries, err := os.ReadDir(netClassDir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == pfName {
+			continue
+		}
+		switchID, err := h.GetPhysSwitchID(name)
+		if err != nil || switchID != pfSwitchID {
+			continue
+		}
+		portName, err := h.GetPhysPortName(name)
+		if err != nil {
+			continue
+		}
+		if portName == wantPortName {
+			return name, nil
+		}
+	}
+
+	// Sub-function representors (e.g. SmartNIC DPUs) don't surface under the PF's phys_switch_id
+	// walk above; they're listed explicitly under the PF's subfunctions directory instead.
+	return h.getSubfunctionVfRepresentor(pfName, vfIndex)
+}
+
+// getSubfunctionVfRepresentor looks for a VF representor among the PF's subfunctions, for
+// topologies (SmartNIC DPUs) where representors are exposed as auxiliary devices rather than
+// directly alongside the PF on the PCI bus.
+func (h *HostManager) getSubfunctionVfRepresentor(pfName string, vfIndex int) (string, error) {
+	subfunctionsDir := filepath.Join(vars.FilesystemRoot, consts.SysClassNet, pfName, "subfunctions")
+	entries, err := os.ReadDir(subfunctionsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	vfPortSuffix := fmt.Sprintf("vf%d", vfIndex)
+	for _, entry := range entries {
+		name := entry.Name()
+		portName, err := h.GetPhysPortName(name)
+		if err != nil {
+			continue
+		}
+		if strings.HasSuffix(portName, vfPortSuffix) {
+			return name, nil
+		}
+	}
+
+	return "", nil
+}
+
 func (h *HostManager) GetNetdevMTU(pciAddr string) int {
 	log.Log.V(2).Info("GetNetdevMTU(): get MTU", "device", pciAddr)
 	ifaceName := h.TryGetInterfaceName(pciAddr)
@@ -202,6 +297,51 @@ func (h *HostManager) GetNetDevLinkSpeed(ifaceName string) string {
 	return fmt.Sprintf("%s Mb/s", strings.TrimSpace(string(data)))
 }
 
+// IsLinkUp reads the netdev's /sys/class/net/<ifaceName>/operstate and returns false only when it
+// reads "down"; any other value, including "unknown", counts as up.
+func (h *HostManager) IsLinkUp(ifaceName string) (bool, error) {
+	log.Log.V(2).Info("IsLinkUp()", "name", ifaceName)
+	operstateFile := filepath.Join(vars.FilesystemRoot, consts.SysClassNet, ifaceName, "operstate")
+	data, err := os.ReadFile(operstateFile)
+	if err != nil {
+		log.Log.Error(err, "IsLinkUp(): fail to read operstate file", "path", operstateFile)
+		return false, err
+	}
+
+	return strings.TrimSpace(string(data)) != "down", nil
+}
+
+// GetNetDevNodeGUID returns the InfiniBand node GUID for the netdev attached to the given PCI
+// address, read from /sys/class/infiniband/<ibdev>/node_guid. It returns an empty string for
+// non-IB devices.
+func (h *HostManager) GetNetDevNodeGUID(pciAddr string) (string, error) {
+	log.Log.V(2).Info("GetNetDevNodeGUID()", "device", pciAddr)
+	ibDevs, err := os.ReadDir(filepath.Join(vars.FilesystemRoot, consts.SysClassInfiniband))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	for _, ibDev := range ibDevs {
+		devLink, err := filepath.EvalSymlinks(filepath.Join(vars.FilesystemRoot, consts.SysClassInfiniband, ibDev.Name(), "device"))
+		if err != nil {
+			continue
+		}
+		if filepath.Base(devLink) != pciAddr {
+			continue
+		}
+		guid, err := os.ReadFile(filepath.Join(vars.FilesystemRoot, consts.SysClassInfiniband, ibDev.Name(), "node_guid"))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(guid)), nil
+	}
+
+	return "", nil
+}
+
 func (h *HostManager) GetLinkType(ifaceStatus sriovnetworkv1.InterfaceExt) string {
 	log.Log.V(2).Info("GetLinkType()", "device", ifaceStatus.PciAddress)
 	if ifaceStatus.Name != "" {
@@ -318,6 +458,14 @@ func (h *HostManager) DiscoverSriovDevices(storeManager StoreManagerInterface) (
 				}
 				for _, vf := range vfs {
 					instance := h.GetVfInfo(vf, devices)
+					if iface.EswitchMode == sriovnetworkv1.ESwithModeSwitchDev {
+						if representor, err := h.GetVfRepresentor(iface.Name, instance.VfID); err != nil {
+							log.Log.Error(err, "DiscoverSriovDevices(): unable to get VF representor, skipping",
+								"device", vf)
+						} else {
+							instance.Representor = representor
+						}
+					}
 					iface.VFs = append(iface.VFs, instance)
 				}
 			}
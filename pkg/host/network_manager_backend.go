@@ -0,0 +1,283 @@
+package host
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
+)
+
+// NetworkManagerBackend abstracts over the host network configuration daemon (NetworkManager,
+// systemd-networkd, or none at all) that needs to be told to keep its hands off the PFs/VFs the
+// operator manages. The concrete backend is auto-detected once at daemon startup (see
+// DetectNetworkManagerBackend), with vars.NetworkManagerBackendOverride available to force a
+// specific one, e.g. on distros where auto-detection guesses wrong.
+type NetworkManagerBackend interface {
+	// Name identifies the backend for logging
+	Name() string
+	// Prepare performs whatever one-time setup the backend needs, and prunes any stale per-PF
+	// state left behind for PF PCI addresses that are no longer in managedPciAddresses
+	Prepare(supportedVfIds []string, managedPciAddresses []string) error
+	// MarkUnmanaged takes pfPciAddress out of the backend's management
+	MarkUnmanaged(pfPciAddress string) error
+	// ClearUnmanaged returns pfPciAddress to the backend's management
+	ClearUnmanaged(pfPciAddress string) error
+}
+
+// nmUdevRule is the udev rule that marks a PF's netdev as unmanaged by NetworkManager, keyed on
+// the PF's PCI address so the rule only ever matches that one device.
+const nmUdevRule = `ACTION=="add|change|move", SUBSYSTEM=="net", KERNELS=="%s", ENV{NM_UNMANAGED}="1"
+`
+
+// nmNetworkManagerBackend marks PFs unmanaged by writing a per-PF "10-nm-disable-<pci>.rules"
+// udev rule, the traditional way of keeping NetworkManager off of SR-IOV VFs.
+type nmNetworkManagerBackend struct{}
+
+func (b *nmNetworkManagerBackend) Name() string {
+	return "NetworkManager"
+}
+
+func (b *nmNetworkManagerBackend) Prepare(supportedVfIds []string, managedPciAddresses []string) error {
+	vars.SupportedVfIds = supportedVfIds
+	return b.pruneStaleRules(managedPciAddresses)
+}
+
+// pruneStaleRules removes "10-nm-disable-<pci>.rules" files for PF PCI addresses that are no
+// longer in managedPciAddresses, e.g. because they moved to ExternallyManaged or were removed
+// from the SriovNetworkNodeState altogether.
+func (b *nmNetworkManagerBackend) pruneStaleRules(managedPciAddresses []string) error {
+	pathFile := filepath.Join(vars.FilesystemRoot, consts.HostUdevRulesFolder)
+	files, err := os.ReadDir(pathFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		log.Log.Error(err, "nmNetworkManagerBackend.pruneStaleRules(): failed to read udev rules dir", "path", pathFile)
+		return err
+	}
+
+	managed := make(map[string]bool, len(managedPciAddresses))
+	for _, pciAddress := range managedPciAddresses {
+		managed[pciAddress] = true
+	}
+
+	for _, file := range files {
+		pciAddress, ok := strings.CutPrefix(file.Name(), "10-nm-disable-")
+		if !ok {
+			continue
+		}
+		pciAddress, ok = strings.CutSuffix(pciAddress, ".rules")
+		if !ok || managed[pciAddress] {
+			continue
+		}
+
+		log.Log.V(2).Info("nmNetworkManagerBackend.pruneStaleRules(): removing stale rule", "device", pciAddress)
+		if err := b.ClearUnmanaged(pciAddress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *nmNetworkManagerBackend) MarkUnmanaged(pfPciAddress string) error {
+	log.Log.V(2).Info("nmNetworkManagerBackend.MarkUnmanaged()", "device", pfPciAddress)
+	pathFile := filepath.Join(vars.FilesystemRoot, consts.HostUdevRulesFolder)
+	if err := os.MkdirAll(pathFile, os.ModePerm); err != nil && !os.IsExist(err) {
+		log.Log.Error(err, "nmNetworkManagerBackend.MarkUnmanaged(): failed to create dir", "path", pathFile)
+		return err
+	}
+
+	udevRuleContent := fmt.Sprintf(nmUdevRule, pfPciAddress)
+	filePath := path.Join(pathFile, fmt.Sprintf("10-nm-disable-%s.rules", pfPciAddress))
+	if err := atomicWriteFile(filePath, []byte(udevRuleContent), 0666); err != nil {
+		log.Log.Error(err, "nmNetworkManagerBackend.MarkUnmanaged(): fail to write file", "path", filePath)
+		return err
+	}
+	return nil
+}
+
+func (b *nmNetworkManagerBackend) ClearUnmanaged(pfPciAddress string) error {
+	pathFile := filepath.Join(vars.FilesystemRoot, consts.HostUdevRulesFolder)
+	filePath := path.Join(pathFile, fmt.Sprintf("10-nm-disable-%s.rules", pfPciAddress))
+	err := atomicRemoveFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// systemdNetworkdUnit is the .network unit that marks a PF as unmanaged by systemd-networkd,
+// matched by PCI path so it only ever applies to that one device.
+const systemdNetworkdUnit = `[Match]
+Path=pci-%s
+
+[Link]
+Unmanaged=yes
+`
+
+// systemdNetworkdBackend marks PFs unmanaged by dropping a "70-sriov-unmanaged-<pci>.network"
+// unit and asking systemd-networkd to reload its configuration, for hosts that run
+// systemd-networkd instead of NetworkManager.
+type systemdNetworkdBackend struct {
+	utilsHelper utils.CmdInterface
+}
+
+func (b *systemdNetworkdBackend) Name() string {
+	return "systemd-networkd"
+}
+
+func (b *systemdNetworkdBackend) Prepare(supportedVfIds []string, managedPciAddresses []string) error {
+	vars.SupportedVfIds = supportedVfIds
+	return b.pruneStaleUnits(managedPciAddresses)
+}
+
+// pruneStaleUnits removes "70-sriov-unmanaged-<pci>.network" files for PF PCI addresses that are
+// no longer in managedPciAddresses, mirroring nmNetworkManagerBackend.pruneStaleRules.
+func (b *systemdNetworkdBackend) pruneStaleUnits(managedPciAddresses []string) error {
+	pathFile := filepath.Join(vars.FilesystemRoot, consts.SystemdNetworkdConfFolder)
+	files, err := os.ReadDir(pathFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		log.Log.Error(err, "systemdNetworkdBackend.pruneStaleUnits(): failed to read networkd config dir", "path", pathFile)
+		return err
+	}
+
+	managed := make(map[string]bool, len(managedPciAddresses))
+	for _, pciAddress := range managedPciAddresses {
+		managed[pciAddress] = true
+	}
+
+	for _, file := range files {
+		pciAddress, ok := strings.CutPrefix(file.Name(), "70-sriov-unmanaged-")
+		if !ok {
+			continue
+		}
+		pciAddress, ok = strings.CutSuffix(pciAddress, ".network")
+		if !ok || managed[pciAddress] {
+			continue
+		}
+
+		log.Log.V(2).Info("systemdNetworkdBackend.pruneStaleUnits(): removing stale unit", "device", pciAddress)
+		if err := b.ClearUnmanaged(pciAddress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *systemdNetworkdBackend) MarkUnmanaged(pfPciAddress string) error {
+	log.Log.V(2).Info("systemdNetworkdBackend.MarkUnmanaged()", "device", pfPciAddress)
+	pathFile := filepath.Join(vars.FilesystemRoot, consts.SystemdNetworkdConfFolder)
+	if err := os.MkdirAll(pathFile, os.ModePerm); err != nil && !os.IsExist(err) {
+		log.Log.Error(err, "systemdNetworkdBackend.MarkUnmanaged(): failed to create dir", "path", pathFile)
+		return err
+	}
+
+	unitContent := fmt.Sprintf(systemdNetworkdUnit, pfPciAddress)
+	filePath := path.Join(pathFile, fmt.Sprintf("70-sriov-unmanaged-%s.network", pfPciAddress))
+	if err := atomicWriteFile(filePath, []byte(unitContent), 0644); err != nil {
+		log.Log.Error(err, "systemdNetworkdBackend.MarkUnmanaged(): fail to write file", "path", filePath)
+		return err
+	}
+	return b.reload()
+}
+
+func (b *systemdNetworkdBackend) ClearUnmanaged(pfPciAddress string) error {
+	pathFile := filepath.Join(vars.FilesystemRoot, consts.SystemdNetworkdConfFolder)
+	filePath := path.Join(pathFile, fmt.Sprintf("70-sriov-unmanaged-%s.network", pfPciAddress))
+	if err := atomicRemoveFile(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return b.reload()
+}
+
+func (b *systemdNetworkdBackend) reload() error {
+	_, stderr, err := b.utilsHelper.RunCommand("networkctl", "reload")
+	if err != nil {
+		log.Log.Error(err, "systemdNetworkdBackend.reload(): failed to reload systemd-networkd", "stderr", stderr)
+		return err
+	}
+	return nil
+}
+
+// noopNetworkManagerBackend is used when systemd is not detected on the host (no
+// /run/systemd/system), or when vars.NetworkManagerBackendOverride is "none". It leaves udev
+// rules/unit files alone entirely; it's on the admin to keep the host stack off our VFs.
+type noopNetworkManagerBackend struct{}
+
+func (b *noopNetworkManagerBackend) Name() string {
+	return "none"
+}
+
+func (b *noopNetworkManagerBackend) Prepare(supportedVfIds []string, _ []string) error {
+	vars.SupportedVfIds = supportedVfIds
+	log.Log.V(2).Info("noopNetworkManagerBackend.Prepare(): no supported host network backend detected, " +
+		"not writing any unmanaged rules; the host network stack must be configured to leave SR-IOV VFs alone")
+	return nil
+}
+
+func (b *noopNetworkManagerBackend) MarkUnmanaged(string) error {
+	return nil
+}
+
+func (b *noopNetworkManagerBackend) ClearUnmanaged(string) error {
+	return nil
+}
+
+// DetectNetworkManagerBackend picks the NetworkManagerBackend to use, honoring
+// vars.NetworkManagerBackendOverride (set from the --network-manager-backend config-daemon flag
+// or the SriovOperatorConfig) before falling back to auto-detection: NetworkManager if
+// NetworkManager.service is active, systemd-networkd if systemd is running but NetworkManager
+// isn't, otherwise none.
+func (h *HostManager) DetectNetworkManagerBackend() NetworkManagerBackend {
+	switch vars.NetworkManagerBackendOverride {
+	case "NetworkManager":
+		return &nmNetworkManagerBackend{}
+	case "systemd-networkd":
+		return &systemdNetworkdBackend{utilsHelper: h.utilsHelper}
+	case "none":
+		return &noopNetworkManagerBackend{}
+	case "":
+		// auto-detect below
+	default:
+		log.Log.Error(nil, "DetectNetworkManagerBackend(): unknown NetworkManagerBackendOverride, falling back to auto-detection",
+			"override", vars.NetworkManagerBackendOverride)
+	}
+
+	if _, err := os.Stat(filepath.Join(vars.FilesystemRoot, "/run/systemd/system")); err != nil {
+		log.Log.V(2).Info("DetectNetworkManagerBackend(): systemd not detected, falling back to no-op backend")
+		return &noopNetworkManagerBackend{}
+	}
+
+	stdout, _, err := h.utilsHelper.RunCommand("systemctl", "is-active", "NetworkManager.service")
+	if err == nil && strings.TrimSpace(stdout) == "active" {
+		return &nmNetworkManagerBackend{}
+	}
+
+	return &systemdNetworkdBackend{utilsHelper: h.utilsHelper}
+}
+
+var (
+	networkManagerBackendOnce sync.Once
+	networkManagerBackendInst NetworkManagerBackend
+)
+
+// networkManagerBackend returns the NetworkManagerBackend detected at the first call, memoized
+// for the lifetime of the daemon process since the host's init system doesn't change at runtime.
+func (h *HostManager) networkManagerBackend() NetworkManagerBackend {
+	networkManagerBackendOnce.Do(func() {
+		networkManagerBackendInst = h.DetectNetworkManagerBackend()
+		log.Log.Info("networkManagerBackend(): selected host network backend", "backend", networkManagerBackendInst.Name())
+	})
+	return networkManagerBackendInst
+}
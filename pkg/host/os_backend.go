@@ -0,0 +1,217 @@
+package host
+
+import (
+	"fmt"
+	"os"
+	pathlib "path"
+	"strings"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const osReleaseFile = "/etc/os-release"
+
+// osRelease holds the fields parsed out of /etc/os-release that distinguish one distro family
+// from another.
+type osRelease struct {
+	id         string
+	idLike     []string
+	variantID  string
+	prettyName string
+}
+
+// OSBackend answers the distro-specific questions hostManager used to resolve with a chain of
+// IsRHELSystem/IsUbuntuSystem/IsCoreOS branches, plus the handful of facts that differ per distro
+// family (where RDMA surfaces itself, what manages its service, how packages and kernel
+// arguments are applied). It's detected once, at NewHostManager construction time, by parsing
+// /etc/os-release; adding support for a new distro family is a matter of registering another
+// backend type rather than adding another branch everywhere these questions get asked.
+type OSBackend interface {
+	// IsRHEL returns true for RHEL and RHEL-derived distros (CentOS, Fedora, RHCOS, ...)
+	IsRHEL() bool
+	// IsCoreOS returns true specifically for CoreOS/RHCOS
+	IsCoreOS() bool
+	// IsUbuntu returns true for Ubuntu/Debian-derived distros
+	IsUbuntu() bool
+	// PrettyName returns the distro's human-readable name (os-release PRETTY_NAME)
+	PrettyName() string
+	// RDMAConditionFile returns the path (relative to the host root) whose presence signals that
+	// the distro's RDMA service has finished initializing.
+	RDMAConditionFile() string
+	// RDMAServiceName returns the systemd unit that manages the RDMA stack on this distro.
+	RDMAServiceName() string
+	// PackageManagerCommand returns the argv of the distro's package manager, as invoked to
+	// install a package (the package name is appended by the caller).
+	PackageManagerCommand() []string
+	// KernelArgMechanism returns the argv of the command used to make a kernel argument change
+	// take effect on the next boot (e.g. regenerating a bootloader config), or nil if this distro
+	// family has no such mechanism.
+	KernelArgMechanism() []string
+}
+
+// rhelBackend is RHEL and its derivatives (CentOS, Fedora, RHCOS).
+type rhelBackend struct {
+	prettyName string
+	coreOS     bool
+}
+
+func (b *rhelBackend) IsRHEL() bool       { return true }
+func (b *rhelBackend) IsCoreOS() bool     { return b.coreOS }
+func (b *rhelBackend) IsUbuntu() bool     { return false }
+func (b *rhelBackend) PrettyName() string { return b.prettyName }
+func (b *rhelBackend) RDMAConditionFile() string {
+	return "/var/lib/rdma/rdma.conf"
+}
+func (b *rhelBackend) RDMAServiceName() string { return "rdma" }
+func (b *rhelBackend) PackageManagerCommand() []string {
+	return []string{"dnf", "install", "-y"}
+}
+func (b *rhelBackend) KernelArgMechanism() []string {
+	return []string{"grubby", "--update-kernel=ALL"}
+}
+
+// ubuntuBackend is Ubuntu/Debian and their derivatives.
+type ubuntuBackend struct {
+	prettyName string
+}
+
+func (b *ubuntuBackend) IsRHEL() bool       { return false }
+func (b *ubuntuBackend) IsCoreOS() bool     { return false }
+func (b *ubuntuBackend) IsUbuntu() bool     { return true }
+func (b *ubuntuBackend) PrettyName() string { return b.prettyName }
+func (b *ubuntuBackend) RDMAConditionFile() string {
+	return "/run/rdma-ndd.pid"
+}
+func (b *ubuntuBackend) RDMAServiceName() string { return "rdma-ndd" }
+func (b *ubuntuBackend) PackageManagerCommand() []string {
+	return []string{"apt-get", "install", "-y"}
+}
+func (b *ubuntuBackend) KernelArgMechanism() []string {
+	return []string{"update-grub"}
+}
+
+// suseBackend is SLES/openSUSE and their derivatives. It's a real, distinct backend rather than
+// an all-false stand-in: IsRHEL/IsCoreOS/IsUbuntu are false for it the same way they would be for
+// "unknown", but its RDMA/package/kernel-arg facts are genuine SUSE ones, so callers that use
+// those (rather than the IsXxx questions) get correct behavior on SUSE instead of empty defaults.
+type suseBackend struct {
+	prettyName string
+}
+
+func (b *suseBackend) IsRHEL() bool       { return false }
+func (b *suseBackend) IsCoreOS() bool     { return false }
+func (b *suseBackend) IsUbuntu() bool     { return false }
+func (b *suseBackend) PrettyName() string { return b.prettyName }
+func (b *suseBackend) RDMAConditionFile() string {
+	return "/var/run/rdma-core/rdma.conf"
+}
+func (b *suseBackend) RDMAServiceName() string { return "rdma-core" }
+func (b *suseBackend) PackageManagerCommand() []string {
+	return []string{"zypper", "install", "-y"}
+}
+func (b *suseBackend) KernelArgMechanism() []string {
+	return []string{"grub2-mkconfig", "-o", "/boot/grub2/grub.cfg"}
+}
+
+// unknownBackend is used when /etc/os-release names a distro family none of the above recognize.
+// It answers the IsXxx questions false and reports no RDMA/package/kernel-arg facts, since none
+// can safely be guessed.
+type unknownBackend struct {
+	prettyName string
+}
+
+func (b *unknownBackend) IsRHEL() bool                    { return false }
+func (b *unknownBackend) IsCoreOS() bool                  { return false }
+func (b *unknownBackend) IsUbuntu() bool                  { return false }
+func (b *unknownBackend) PrettyName() string              { return b.prettyName }
+func (b *unknownBackend) RDMAConditionFile() string       { return "" }
+func (b *unknownBackend) RDMAServiceName() string         { return "" }
+func (b *unknownBackend) PackageManagerCommand() []string { return nil }
+func (b *unknownBackend) KernelArgMechanism() []string    { return nil }
+
+// rhelFamilyIDs, ubuntuFamilyIDs and suseFamilyIDs classify the os-release ID/ID_LIKE tokens
+// (lowercased) that select each backend type.
+var (
+	rhelFamilyIDs   = map[string]bool{"rhel": true, "centos": true, "fedora": true, "rhcos": true}
+	ubuntuFamilyIDs = map[string]bool{"ubuntu": true, "debian": true}
+	suseFamilyIDs   = map[string]bool{"sles": true, "opensuse": true, "opensuse-leap": true, "sle-micro": true}
+)
+
+// detectOSBackend reads /etc/os-release off the host and returns the OSBackend matching its
+// ID/ID_LIKE/VARIANT_ID fields.
+func detectOSBackend() (OSBackend, error) {
+	release, err := readOSRelease()
+	if err != nil {
+		return nil, err
+	}
+	return identifyOSBackend(release), nil
+}
+
+// identifyOSBackend picks the concrete OSBackend type matching release's ID/ID_LIKE tokens.
+// Adding a new distro family means registering another backend type and token set here, not
+// adding an if/else branch to every caller.
+func identifyOSBackend(release osRelease) OSBackend {
+	tokens := append([]string{release.id}, release.idLike...)
+
+	for _, token := range tokens {
+		token = strings.ToLower(token)
+		switch {
+		case rhelFamilyIDs[token]:
+			return &rhelBackend{
+				prettyName: release.prettyName,
+				coreOS:     strings.EqualFold(release.variantID, "coreos"),
+			}
+		case ubuntuFamilyIDs[token]:
+			return &ubuntuBackend{prettyName: release.prettyName}
+		case suseFamilyIDs[token]:
+			return &suseBackend{prettyName: release.prettyName}
+		}
+	}
+
+	return &unknownBackend{prettyName: release.prettyName}
+}
+
+// readOSRelease parses the host's /etc/os-release file (following the os-release(5) KEY=VALUE
+// format, values optionally double-quoted) into an osRelease.
+func readOSRelease() (osRelease, error) {
+	path := osReleaseFile
+	if !vars.UsingSystemdMode {
+		path = pathlib.Join(hostPathFromDaemon, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return osRelease{}, fmt.Errorf("readOSRelease(): failed to read %s: %w", path, err)
+	}
+
+	release := parseOSRelease(data)
+	if release.id == "" {
+		return osRelease{}, fmt.Errorf("readOSRelease(): %s has no ID field", path)
+	}
+	return release, nil
+}
+
+// parseOSRelease parses the contents of an os-release(5) file (KEY=VALUE per line, values
+// optionally double-quoted) into an osRelease.
+func parseOSRelease(data []byte) osRelease {
+	release := osRelease{}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		switch strings.TrimSpace(key) {
+		case "ID":
+			release.id = value
+		case "ID_LIKE":
+			release.idLike = strings.Fields(value)
+		case "VARIANT_ID":
+			release.variantID = value
+		case "PRETTY_NAME":
+			release.prettyName = value
+		}
+	}
+	return release
+}
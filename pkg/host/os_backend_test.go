@@ -0,0 +1,81 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sles15OSRelease = `NAME="SLES"
+VERSION="15-SP5"
+VERSION_ID="15.5"
+PRETTY_NAME="SUSE Linux Enterprise Server 15 SP5"
+ID="sles"
+ID_LIKE="suse"
+`
+
+const debian12OSRelease = `PRETTY_NAME="Debian GNU/Linux 12 (bookworm)"
+NAME="Debian GNU/Linux"
+VERSION_ID="12"
+VERSION="12 (bookworm)"
+ID=debian
+`
+
+const rhcos9OSRelease = `NAME="Red Hat Enterprise Linux CoreOS"
+ID="rhcos"
+ID_LIKE="rhel fedora"
+VARIANT="CoreOS"
+VARIANT_ID="coreos"
+PRETTY_NAME="Red Hat Enterprise Linux CoreOS 9.4"
+`
+
+func TestIdentifyOSBackendSLES(t *testing.T) {
+	backend := identifyOSBackend(parseOSRelease([]byte(sles15OSRelease)))
+	assert.IsType(t, &suseBackend{}, backend)
+	assert.False(t, backend.IsRHEL())
+	assert.False(t, backend.IsUbuntu())
+	assert.False(t, backend.IsCoreOS())
+	assert.Equal(t, "SUSE Linux Enterprise Server 15 SP5", backend.PrettyName())
+	assert.Equal(t, "rdma-core", backend.RDMAServiceName())
+	assert.NotEmpty(t, backend.RDMAConditionFile())
+	assert.Equal(t, []string{"zypper", "install", "-y"}, backend.PackageManagerCommand())
+	assert.Equal(t, []string{"grub2-mkconfig", "-o", "/boot/grub2/grub.cfg"}, backend.KernelArgMechanism())
+}
+
+func TestIdentifyOSBackendDebian(t *testing.T) {
+	backend := identifyOSBackend(parseOSRelease([]byte(debian12OSRelease)))
+	assert.IsType(t, &ubuntuBackend{}, backend)
+	assert.False(t, backend.IsRHEL())
+	assert.True(t, backend.IsUbuntu())
+	assert.False(t, backend.IsCoreOS())
+	assert.Equal(t, "Debian GNU/Linux 12 (bookworm)", backend.PrettyName())
+	assert.Equal(t, "rdma-ndd", backend.RDMAServiceName())
+	assert.NotEmpty(t, backend.RDMAConditionFile())
+	assert.Equal(t, []string{"apt-get", "install", "-y"}, backend.PackageManagerCommand())
+	assert.Equal(t, []string{"update-grub"}, backend.KernelArgMechanism())
+}
+
+func TestIdentifyOSBackendRHCOS(t *testing.T) {
+	backend := identifyOSBackend(parseOSRelease([]byte(rhcos9OSRelease)))
+	assert.IsType(t, &rhelBackend{}, backend)
+	assert.True(t, backend.IsRHEL())
+	assert.False(t, backend.IsUbuntu())
+	assert.True(t, backend.IsCoreOS())
+	assert.Equal(t, "rdma", backend.RDMAServiceName())
+	assert.NotEmpty(t, backend.RDMAConditionFile())
+	assert.Equal(t, []string{"dnf", "install", "-y"}, backend.PackageManagerCommand())
+	assert.Equal(t, []string{"grubby", "--update-kernel=ALL"}, backend.KernelArgMechanism())
+}
+
+func TestIdentifyOSBackendUnknown(t *testing.T) {
+	backend := identifyOSBackend(osRelease{id: "plan9", prettyName: "Plan 9"})
+	assert.IsType(t, &unknownBackend{}, backend)
+	assert.False(t, backend.IsRHEL())
+	assert.False(t, backend.IsUbuntu())
+	assert.False(t, backend.IsCoreOS())
+	assert.Equal(t, "Plan 9", backend.PrettyName())
+	assert.Empty(t, backend.RDMAConditionFile())
+	assert.Empty(t, backend.RDMAServiceName())
+	assert.Nil(t, backend.PackageManagerCommand())
+	assert.Nil(t, backend.KernelArgMechanism())
+}
@@ -0,0 +1,220 @@
+package host
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// RDMA_NL_NLDEV (genetlink family "rdma_nl") command/attribute numbers, from
+// include/uapi/rdma/rdma_netlink.h. golang.org/x/sys/unix doesn't define these, since they belong
+// to a dynamically-registered genetlink family rather than a fixed netlink family.
+const (
+	rdmaNlFamilyName = "rdma_nl"
+
+	rdmaNldevCmdSysGet = 25 // RDMA_NLDEV_CMD_SYS_GET
+	rdmaNldevCmdSysSet = 26 // RDMA_NLDEV_CMD_SYS_SET
+
+	rdmaNldevAttrSysNetnsMode = 72 // RDMA_NLDEV_SYS_ATTR_NETNS_MODE
+)
+
+// rdmaNetnsModeShared/Exclusive are the two values RDMA_NLDEV_SYS_ATTR_NETNS_MODE takes: 0
+// means every RDMA device lives in a single, shared network namespace; 1 means each device can be
+// moved into its own namespace ("exclusive").
+const (
+	rdmaNetnsModeShared    uint8 = 0
+	rdmaNetnsModeExclusive uint8 = 1
+)
+
+// getRDMASubsystemMode reads the kernel's RDMA subsystem network-namespace mode via the
+// RDMA_NLDEV_CMD_SYS_GET genetlink request, the netlink equivalent of `rdma system show netns`.
+func getRDMASubsystemMode() (uint8, error) {
+	conn, err := newGenlConn()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	familyID, err := conn.resolveFamily(rdmaNlFamilyName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve %s genetlink family: %w", rdmaNlFamilyName, err)
+	}
+
+	attrs, err := conn.execute(familyID, rdmaNldevCmdSysGet, nil)
+	if err != nil {
+		return 0, fmt.Errorf("RDMA_NLDEV_CMD_SYS_GET failed: %w", err)
+	}
+
+	raw, ok := attrs[rdmaNldevAttrSysNetnsMode]
+	if !ok || len(raw) < 1 {
+		return 0, fmt.Errorf("RDMA_NLDEV_CMD_SYS_GET response is missing RDMA_NLDEV_SYS_ATTR_NETNS_MODE")
+	}
+	return raw[0], nil
+}
+
+// setRDMASubsystemMode sets the kernel's RDMA subsystem network-namespace mode via the
+// RDMA_NLDEV_CMD_SYS_SET genetlink request, the netlink equivalent of `rdma system set netns`.
+func setRDMASubsystemMode(mode uint8) error {
+	conn, err := newGenlConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	familyID, err := conn.resolveFamily(rdmaNlFamilyName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s genetlink family: %w", rdmaNlFamilyName, err)
+	}
+
+	attr := nlAttr(rdmaNldevAttrSysNetnsMode, []byte{mode})
+	if _, err := conn.execute(familyID, rdmaNldevCmdSysSet, attr); err != nil {
+		return fmt.Errorf("RDMA_NLDEV_CMD_SYS_SET failed: %w", err)
+	}
+	return nil
+}
+
+// genlConn is a minimal generic-netlink client, just enough to resolve a family by name and run a
+// single request/response exchange against it. It exists so RDMA subsystem mode can be read and
+// set without shelling out to the `rdma` binary, which isn't guaranteed to be present in the
+// operator's container image.
+type genlConn struct {
+	fd  int
+	seq uint32
+}
+
+func newGenlConn() (*genlConn, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW, unix.NETLINK_GENERIC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open generic netlink socket: %w", err)
+	}
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to bind generic netlink socket: %w", err)
+	}
+	return &genlConn{fd: fd}, nil
+}
+
+func (c *genlConn) Close() error {
+	return unix.Close(c.fd)
+}
+
+// resolveFamily looks up a genetlink family's numeric ID by name via CTRL_CMD_GETFAMILY, since
+// genetlink families like rdma_nl aren't assigned a fixed ID ahead of time.
+func (c *genlConn) resolveFamily(name string) (uint16, error) {
+	attrs, err := c.execute(unix.GENL_ID_CTRL, unix.CTRL_CMD_GETFAMILY, nlAttr(unix.CTRL_ATTR_FAMILY_NAME, nlString(name)))
+	if err != nil {
+		return 0, err
+	}
+
+	raw, ok := attrs[unix.CTRL_ATTR_FAMILY_ID]
+	if !ok || len(raw) < 2 {
+		return 0, fmt.Errorf("genetlink family %q not found", name)
+	}
+	return binary.NativeEndian.Uint16(raw), nil
+}
+
+// execute sends a single genetlink request and returns the attributes of the first reply
+// message. It isn't meant to handle multi-message (NLM_F_MULTI) dumps, which SYS_GET/SYS_SET and
+// CTRL_CMD_GETFAMILY don't use.
+func (c *genlConn) execute(familyID uint16, cmd uint8, attrs []byte) (map[uint16][]byte, error) {
+	c.seq++
+	req := newGenlMessage(familyID, cmd, c.seq, attrs)
+	if err := unix.Send(c.fd, req, 0); err != nil {
+		return nil, fmt.Errorf("failed to send netlink request: %w", err)
+	}
+
+	buf := make([]byte, unix.Getpagesize())
+	n, err := unix.Read(c.fd, buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read netlink response: %w", err)
+	}
+
+	return parseGenlReply(buf[:n])
+}
+
+const (
+	nlHdrLen   = 16 // struct nlmsghdr
+	genlHdrLen = 4  // struct genlmsghdr
+)
+
+// newGenlMessage builds a single netlink message wrapping a genetlink request: nlmsghdr +
+// genlmsghdr + already-encoded attributes.
+func newGenlMessage(familyID uint16, cmd uint8, seq uint32, attrs []byte) []byte {
+	payloadLen := genlHdrLen + len(attrs)
+	msg := make([]byte, nlHdrLen+payloadLen)
+
+	binary.NativeEndian.PutUint32(msg[0:4], uint32(len(msg)))
+	binary.NativeEndian.PutUint16(msg[4:6], familyID)
+	binary.NativeEndian.PutUint16(msg[6:8], unix.NLM_F_REQUEST|unix.NLM_F_ACK)
+	binary.NativeEndian.PutUint32(msg[8:12], seq)
+	// msg[12:16] (pid) left as 0: the kernel fills in the sending socket's port ID
+
+	msg[16] = cmd // genlmsghdr.cmd
+	msg[17] = 1   // genlmsghdr.version
+	// msg[18:20] (genlmsghdr.reserved) left as 0
+
+	copy(msg[nlHdrLen+genlHdrLen:], attrs)
+	return msg
+}
+
+// parseGenlReply walks the netlink attributes in a genetlink reply, returning them keyed by
+// attribute type. It errors out on an NLMSG_ERROR message, which the kernel sends in place of the
+// expected reply when the request itself failed (e.g. RDMA_NLDEV_SYS_ATTR_NETNS_MODE rejected
+// because RDMA devices are still in use).
+func parseGenlReply(buf []byte) (map[uint16][]byte, error) {
+	if len(buf) < nlHdrLen {
+		return nil, fmt.Errorf("netlink response too short")
+	}
+
+	msgType := binary.NativeEndian.Uint16(buf[4:6])
+	if msgType == unix.NLMSG_ERROR {
+		if len(buf) < nlHdrLen+4 {
+			return nil, fmt.Errorf("netlink error response too short")
+		}
+		errno := int32(binary.NativeEndian.Uint32(buf[nlHdrLen : nlHdrLen+4]))
+		if errno != 0 {
+			return nil, unix.Errno(-errno)
+		}
+		return map[uint16][]byte{}, nil
+	}
+
+	if len(buf) < nlHdrLen+genlHdrLen {
+		return nil, fmt.Errorf("genetlink response too short")
+	}
+
+	return parseNlAttrs(buf[nlHdrLen+genlHdrLen:])
+}
+
+func parseNlAttrs(buf []byte) (map[uint16][]byte, error) {
+	attrs := make(map[uint16][]byte)
+	for len(buf) >= 4 {
+		attrLen := binary.NativeEndian.Uint16(buf[0:2])
+		attrType := binary.NativeEndian.Uint16(buf[2:4]) &^ unix.NLA_F_NESTED &^ unix.NLA_F_NET_BYTEORDER
+		if int(attrLen) < 4 || int(attrLen) > len(buf) {
+			return nil, fmt.Errorf("malformed netlink attribute")
+		}
+		attrs[attrType] = buf[4:attrLen]
+		buf = buf[nlAlign(int(attrLen)):]
+	}
+	return attrs, nil
+}
+
+// nlAttr encodes a single netlink attribute (header + value, padded to a 4-byte boundary).
+func nlAttr(attrType uint16, value []byte) []byte {
+	attrLen := 4 + len(value)
+	buf := make([]byte, nlAlign(attrLen))
+	binary.NativeEndian.PutUint16(buf[0:2], uint16(attrLen))
+	binary.NativeEndian.PutUint16(buf[2:4], attrType)
+	copy(buf[4:], value)
+	return buf
+}
+
+// nlString encodes a Go string as a NUL-terminated netlink attribute value.
+func nlString(s string) []byte {
+	return append([]byte(s), 0)
+}
+
+func nlAlign(n int) int {
+	return (n + 3) &^ 3
+}
@@ -1,6 +1,7 @@
 package host
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path"
@@ -17,10 +18,22 @@ import (
 // TODO: handle this to support unit-tests
 const systemdDir = "/usr/lib/systemd/system/"
 
+// systemdDropInDir is where systemd looks for unit drop-in overrides, regardless of where the
+// base unit file itself lives.
+const systemdDropInDir = "/etc/systemd/system/"
+
 type Service struct {
 	Name    string
 	Path    string
 	Content string
+	DropIns []DropIn
+}
+
+// DropIn represents a single systemd drop-in file (e.g. /etc/systemd/system/<unit>.d/<name>.conf)
+// that overrides or extends a subset of the base unit's options without rewriting the whole file.
+type DropIn struct {
+	Name    string
+	Content string
 }
 
 func NewService(name, path, content string) *Service {
@@ -31,6 +44,16 @@ func NewService(name, path, content string) *Service {
 	}
 }
 
+// dropInDir returns the drop-in directory for a given systemd unit name, e.g. "foo.service" -> ".../foo.service.d"
+func dropInDir(serviceName string) string {
+	return path.Join(consts.Chroot, systemdDropInDir, serviceName+".d")
+}
+
+// dropInPath returns the full path of a named drop-in file for a given unit
+func dropInPath(serviceName, dropInName string) string {
+	return path.Join(dropInDir(serviceName), dropInName+".conf")
+}
+
 // ServiceInjectionManifestFile service injection manifest file structure
 type ServiceInjectionManifestFile struct {
 	Name    string
@@ -204,7 +227,8 @@ OUTER:
 	}, nil
 }
 
-// ReadServiceInjectionManifestFile reads service injection file
+// ReadServiceInjectionManifestFile reads service injection file and returns the base unit
+// together with its drop-ins, rather than flattening the drop-in content into Content.
 func (h *HostManager) ReadServiceInjectionManifestFile(path string) (*Service, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -216,13 +240,96 @@ func (h *HostManager) ReadServiceInjectionManifestFile(path string) (*Service, e
 		return nil, err
 	}
 
+	dropIns := make([]DropIn, 0, len(serviceContent.Dropins))
+	for i, dropin := range serviceContent.Dropins {
+		dropIns = append(dropIns, DropIn{
+			Name:    fmt.Sprintf("90-%s-%d", serviceContent.Name, i),
+			Content: dropin.Contents,
+		})
+	}
+
 	return &Service{
 		Name:    serviceContent.Name,
 		Path:    systemdDir + serviceContent.Name,
-		Content: serviceContent.Dropins[0].Contents,
+		DropIns: dropIns,
 	}, nil
 }
 
+// ReadServiceWithDropIns reads the base unit file as well as any drop-ins already installed for it
+func (h *HostManager) ReadServiceWithDropIns(servicePath string) (*Service, error) {
+	service, err := h.ReadService(servicePath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dropInDir(service.Name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return service, nil
+		}
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".conf") {
+			continue
+		}
+		data, err := os.ReadFile(path.Join(dropInDir(service.Name), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		service.DropIns = append(service.DropIns, DropIn{
+			Name:    strings.TrimSuffix(entry.Name(), ".conf"),
+			Content: string(data),
+		})
+	}
+
+	return service, nil
+}
+
+// EnsureDropIn writes (or overwrites) a single drop-in file for a service and reloads systemd so
+// the change is picked up, without touching the rest of the unit's configuration.
+func (h *HostManager) EnsureDropIn(service *Service, dropIn *DropIn) error {
+	dir := dropInDir(service.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Log.Error(err, "EnsureDropIn(): failed to create drop-in dir", "path", dir)
+		return err
+	}
+
+	filePath := dropInPath(service.Name, dropIn.Name)
+	if err := os.WriteFile(filePath, []byte(dropIn.Content), 0644); err != nil {
+		log.Log.Error(err, "EnsureDropIn(): failed to write drop-in", "path", filePath)
+		return err
+	}
+
+	return h.daemonReload()
+}
+
+// RemoveDropIn removes a named drop-in file for a service and reloads systemd
+func (h *HostManager) RemoveDropIn(service *Service, dropInName string) error {
+	filePath := dropInPath(service.Name, dropInName)
+	if err := os.Remove(filePath); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		log.Log.Error(err, "RemoveDropIn(): failed to remove drop-in", "path", filePath)
+		return err
+	}
+
+	return h.daemonReload()
+}
+
+func (h *HostManager) daemonReload() error {
+	exit, err := h.utilsHelper.Chroot(consts.Chroot)
+	if err != nil {
+		return err
+	}
+	defer exit()
+
+	_, _, err = h.utilsHelper.RunCommand("systemctl", "daemon-reload")
+	return err
+}
+
 // ReadServiceManifestFile reads service file
 func (h *HostManager) ReadServiceManifestFile(path string) (*Service, error) {
 	data, err := os.ReadFile(path)
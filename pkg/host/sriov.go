@@ -1,30 +1,58 @@
 package host
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jaypipes/ghw"
 	"github.com/vishvananda/netlink"
+	"golang.org/x/sync/errgroup"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
-	dputils "github.com/k8snetworkplumbingwg/sriov-network-device-plugin/pkg/utils"
-
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/consts"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/vars"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
-	mlx "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vendors/mellanox"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vendors"
+	_ "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vendors/mellanox" // registers the Mellanox VendorHandler
 )
 
+// ErrSRIOVUnsupported is returned when a PF doesn't expose a sriov_numvfs control file at all,
+// i.e. it isn't actually SR-IOV capable at the kernel level (e.g. a NIC that was mis-selected as
+// an SR-IOV PF). SetSriovNumVfs checks for this up front so callers get this clear sentinel
+// instead of a cryptic ENOENT from the numVfs write.
+var ErrSRIOVUnsupported = errors.New("device is not SR-IOV capable")
+
+// SRIOVCapable reports whether pfPciAddr exposes a sriov_numvfs sysfs control file, i.e. whether
+// the kernel considers it an SR-IOV capable PF at all.
+func (h *HostManager) SRIOVCapable(pfPciAddr string) (bool, error) {
+	numVfsFilePath := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pfPciAddr, consts.NumVfsFile)
+	if _, err := os.Stat(numVfsFilePath); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("SRIOVCapable(): failed to stat %s: %w", numVfsFilePath, err)
+	}
+	return true, nil
+}
+
 func (h *HostManager) SetSriovNumVfs(pciAddr string, numVfs int) error {
 	log.Log.V(2).Info("SetSriovNumVfs(): set NumVfs", "device", pciAddr, "numVfs", numVfs)
+	if capable, err := h.SRIOVCapable(pciAddr); err != nil {
+		return err
+	} else if !capable {
+		log.Log.Error(ErrSRIOVUnsupported, "SetSriovNumVfs(): device does not expose sriov_numvfs", "device", pciAddr)
+		return ErrSRIOVUnsupported
+	}
 	numVfsFilePath := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, pciAddr, consts.NumVfsFile)
 	bs := []byte(strconv.Itoa(numVfs))
 	err := os.WriteFile(numVfsFilePath, []byte("0"), os.ModeAppend)
@@ -42,7 +70,15 @@ func (h *HostManager) SetSriovNumVfs(pciAddr string, numVfs int) error {
 
 func (h *HostManager) ResetSriovDevice(ifaceStatus sriovnetworkv1.InterfaceExt) error {
 	log.Log.V(2).Info("ResetSriovDevice(): reset SRIOV device", "address", ifaceStatus.PciAddress)
-	if err := h.SetSriovNumVfs(ifaceStatus.PciAddress, 0); err != nil {
+	if err := h.RemoveVfRepresentorUdevRule(ifaceStatus.PciAddress); err != nil {
+		log.Log.Error(err, "ResetSriovDevice(): failed to remove VF representor udev rule", "address", ifaceStatus.PciAddress)
+	}
+	if err := h.RemoveVfConfigUdevRules(ifaceStatus.PciAddress); err != nil {
+		log.Log.Error(err, "ResetSriovDevice(): failed to remove VF config udev rules", "address", ifaceStatus.PciAddress)
+	}
+	// A PF that was never actually SR-IOV capable (e.g. mis-selected) has nothing to reset;
+	// treat ErrSRIOVUnsupported as a no-op rather than failing the reset.
+	if err := h.SetSriovNumVfs(ifaceStatus.PciAddress, 0); err != nil && !errors.Is(err, ErrSRIOVUnsupported) {
 		return err
 	}
 	if ifaceStatus.LinkType == consts.LinkTypeETH {
@@ -65,12 +101,58 @@ func (h *HostManager) ResetSriovDevice(ifaceStatus sriovnetworkv1.InterfaceExt)
 	return nil
 }
 
+// HasOutOfBandDrift reports whether the host's live PF/VF state has drifted out from under the
+// operator in ways sriovnetworkv1.NeedToUpdateSriov doesn't see, because it only diffs
+// NumVfs/MTU/LinkType/EswitchMode on the SriovNetworkNodeState status: an admin taking the PF link
+// down, flipping eswitch mode at the kernel level, or clearing an IB VF's GUID, all leave those
+// fields untouched. ConfigSriovInterfaces treats a true result the same as NeedToUpdateSriov:
+// reconcile the device again instead of skipping it as already in sync.
+func (h *HostManager) HasOutOfBandDrift(iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt) bool {
+	if up, err := h.IsLinkUp(ifaceStatus.Name); err == nil && !up {
+		log.Log.V(2).Info("HasOutOfBandDrift(): PF link is down", "device", ifaceStatus.PciAddress)
+		return true
+	}
+
+	if iface.EswitchMode != "" {
+		if mode, err := h.GetNicSriovMode(ifaceStatus.PciAddress); err == nil && mode != "" && mode != iface.EswitchMode {
+			log.Log.V(2).Info("HasOutOfBandDrift(): eswitch mode drifted", "device", ifaceStatus.PciAddress, "want", iface.EswitchMode, "have", mode)
+			return true
+		}
+	}
+
+	if strings.EqualFold(ifaceStatus.LinkType, consts.LinkTypeIB) {
+		for _, vf := range ifaceStatus.VFs {
+			guid, err := h.GetNetDevNodeGUID(vf.PciAddress)
+			if err == nil && isZeroGUID(guid) {
+				log.Log.V(2).Info("HasOutOfBandDrift(): VF GUID cleared out of band", "device", vf.PciAddress)
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isZeroGUID reports whether guid is the all-zero InfiniBand GUID (e.g. "0000:0000:0000:0000"),
+// which is what a node/port GUID reads as after being cleared out of band.
+func isZeroGUID(guid string) bool {
+	if guid == "" {
+		return false
+	}
+	for _, r := range guid {
+		if r != '0' && r != ':' {
+			return false
+		}
+	}
+	return true
+}
+
 func (h *HostManager) GetVfInfo(pciAddr string, devices []*ghw.PCIDevice) sriovnetworkv1.VirtualFunction {
-	driver, err := dputils.GetDriverName(pciAddr)
+	driver, err := dpUtils.GetDriverName(pciAddr)
 	if err != nil {
 		log.Log.Error(err, "getVfInfo(): unable to parse device driver", "device", pciAddr)
 	}
-	id, err := dputils.GetVFID(pciAddr)
+	id, err := dpUtils.GetVFID(pciAddr)
 	if err != nil {
 		log.Log.Error(err, "getVfInfo(): unable to get VF index", "device", pciAddr)
 	}
@@ -101,7 +183,7 @@ func (h *HostManager) GetVfInfo(pciAddr string, devices []*ghw.PCIDevice) sriovn
 
 func (h *HostManager) SetVfGUID(vfAddr string, pfLink netlink.Link) error {
 	log.Log.Info("SetVfGUID()", "vf", vfAddr)
-	vfID, err := dputils.GetVFID(vfAddr)
+	vfID, err := dpUtils.GetVFID(vfAddr)
 	if err != nil {
 		log.Log.Error(err, "SetVfGUID(): unable to get VF id", "address", vfAddr)
 		return err
@@ -113,7 +195,9 @@ func (h *HostManager) SetVfGUID(vfAddr string, pfLink netlink.Link) error {
 	if err := netlink.LinkSetVfPortGUID(pfLink, vfID, guid); err != nil {
 		return err
 	}
-	if err = h.Unbind(vfAddr); err != nil {
+	// The VF may already be unbound (e.g. bound to a DPDK driver outside the kernel), which is
+	// fine here: the GUID is set at the PF level above regardless.
+	if err = h.Unbind(vfAddr); err != nil && !errors.Is(err, ErrNoDriverBound) {
 		return err
 	}
 
@@ -141,7 +225,7 @@ func (h *HostManager) VFIsReady(pciAddr string) (netlink.Link, error) {
 func (h *HostManager) SetVfAdminMac(vfAddr string, pfLink, vfLink netlink.Link) error {
 	log.Log.Info("SetVfAdminMac()", "vf", vfAddr)
 
-	vfID, err := dputils.GetVFID(vfAddr)
+	vfID, err := dpUtils.GetVFID(vfAddr)
 	if err != nil {
 		log.Log.Error(err, "SetVfAdminMac(): unable to get VF id", "address", vfAddr)
 		return err
@@ -154,6 +238,232 @@ func (h *HostManager) SetVfAdminMac(vfAddr string, pfLink, vfLink netlink.Link)
 	return nil
 }
 
+// EnsureVFAddress captures the VF's current MAC (Ethernet) or node/port GUID (InfiniBand) from its
+// netdev/IB device and re-applies it on the PF side via netlink, so the address survives a driver
+// transition (e.g. to vfio-pci/uio_pci_generic and back) instead of coming up random. It's a no-op
+// if the VF doesn't have an address to capture yet (e.g. its netdev hasn't appeared).
+func (h *HostManager) EnsureVFAddress(pfPciAddr, vfPciAddr, linkType string) error {
+	log.Log.Info("EnsureVFAddress()", "pf", pfPciAddr, "vf", vfPciAddr)
+
+	vfID, err := dpUtils.GetVFID(vfPciAddr)
+	if err != nil {
+		log.Log.Error(err, "EnsureVFAddress(): unable to get VF id", "address", vfPciAddr)
+		return err
+	}
+
+	pfName := h.TryGetInterfaceName(pfPciAddr)
+	pfLink, err := netlink.LinkByName(pfName)
+	if err != nil {
+		log.Log.Error(err, "EnsureVFAddress(): unable to get PF link", "pf", pfPciAddr)
+		return err
+	}
+
+	if strings.EqualFold(linkType, consts.LinkTypeIB) {
+		guid, err := h.GetNetDevNodeGUID(vfPciAddr)
+		if err != nil || guid == "" || isZeroGUID(guid) {
+			return nil
+		}
+		hwAddr, err := parseIBGUID(guid)
+		if err != nil {
+			log.Log.Error(err, "EnsureVFAddress(): failed to parse VF GUID", "address", vfPciAddr, "guid", guid)
+			return err
+		}
+		if err := netlink.LinkSetVfNodeGUID(pfLink, vfID, hwAddr); err != nil {
+			return err
+		}
+		return netlink.LinkSetVfPortGUID(pfLink, vfID, hwAddr)
+	}
+
+	mac := h.GetNetDevMac(h.TryGetInterfaceName(vfPciAddr))
+	if mac == "" {
+		return nil
+	}
+	hwAddr, err := net.ParseMAC(mac)
+	if err != nil {
+		log.Log.Error(err, "EnsureVFAddress(): failed to parse VF mac", "address", vfPciAddr, "mac", mac)
+		return err
+	}
+	return netlink.LinkSetVfHardwareAddr(pfLink, vfID, hwAddr)
+}
+
+// parseIBGUID converts the colon-separated 4x4-hex-digit InfiniBand GUID format
+// GetNetDevNodeGUID returns (e.g. "0002:c903:0003:97f1") into the 8-byte net.HardwareAddr
+// netlink.LinkSetVfNodeGUID/LinkSetVfPortGUID expect.
+func parseIBGUID(guid string) (net.HardwareAddr, error) {
+	groups := strings.Split(guid, ":")
+	if len(groups) != 4 {
+		return nil, fmt.Errorf("unexpected IB GUID format %q", guid)
+	}
+	raw, err := hex.DecodeString(strings.Join(groups, ""))
+	if err != nil || len(raw) != 8 {
+		return nil, fmt.Errorf("unexpected IB GUID format %q", guid)
+	}
+	return net.HardwareAddr(raw), nil
+}
+
+// vfConfigConcurrency bounds how many of a PF's VFs are configured at once within
+// ConfigSriovDevice. VFIsReady alone can block up to 10s per VF, so on a 63-VF PF a serial loop
+// dominates the reconciliation cycle; the bound keeps a single PF from spawning 63 goroutines.
+const vfConfigConcurrency = 16
+
+// configureVF applies the per-VF configuration (GUID/admin MAC, driver bind, MTU) that used to
+// live inline in ConfigSriovDevice's VF loop. It's safe to run concurrently for different VFs of
+// the same PF: each VF's state lives under its own PCI address, and pfLink is only read from, not
+// mutated.
+func (h *HostManager) configureVF(iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt, pfLink netlink.Link, addr string) error {
+	var group *sriovnetworkv1.VfGroup
+
+	vfID, err := dpUtils.GetVFID(addr)
+	if err != nil {
+		log.Log.Error(err, "configSriovDevice(): unable to get VF id", "device", iface.PciAddress)
+		return err
+	}
+
+	for i := range iface.VfGroups {
+		if sriovnetworkv1.IndexInRange(vfID, iface.VfGroups[i].VfRange) {
+			group = &iface.VfGroups[i]
+			break
+		}
+	}
+
+	// VF group not found.
+	if group == nil {
+		return nil
+	}
+
+	// LinkType is an optional field. Let's fallback to current link type
+	// if nothing is specified in the SriovNodePolicy
+	linkType := iface.LinkType
+	if linkType == "" {
+		linkType = ifaceStatus.LinkType
+	}
+
+	// only set GUID and MAC for VF with default driver
+	// for userspace drivers like vfio we configure the vf mac using the kernel nic mac address
+	// before we switch to the userspace driver
+	if yes, d := h.HasDriver(addr); yes && !sriovnetworkv1.StringInArray(d, vars.DpdkDrivers) {
+		if strings.EqualFold(linkType, consts.LinkTypeIB) {
+			if err = h.SetVfGUID(addr, pfLink); err != nil {
+				return err
+			}
+		} else {
+			vfLink, err := h.VFIsReady(addr)
+			if err != nil {
+				log.Log.Error(err, "configSriovDevice(): VF link is not ready", "address", addr)
+				err = h.RebindVfToDefaultDriver(addr)
+				if err != nil {
+					log.Log.Error(err, "configSriovDevice(): failed to rebind VF", "address", addr)
+					return err
+				}
+
+				// Try to check the VF status again
+				vfLink, err = h.VFIsReady(addr)
+				if err != nil {
+					log.Log.Error(err, "configSriovDevice(): VF link is not ready", "address", addr)
+					return err
+				}
+			}
+			if err = h.SetVfAdminMac(addr, pfLink, vfLink); err != nil {
+				log.Log.Error(err, "configSriovDevice(): fail to configure VF admin mac", "device", addr)
+				return err
+			}
+		}
+	} else if h.IsSwitchdev(iface.Name) && sriovnetworkv1.StringInArray(group.DeviceType, vars.DpdkDrivers) && !h.HasUserspaceDriver(addr) {
+		// VFs headed for a userspace driver in switchdev/HW-offload mode can only have
+		// their admin MAC set while they still carry a kernel driver, so do that now and
+		// let the unbind/BindDpdkDriver calls below move the VF to its userspace driver.
+		if err = h.configSwitchdevVfAdminMac(addr, vfID, pfLink, iface, group); err != nil {
+			return err
+		}
+	}
+
+	if err = h.UnbindDriverIfNeeded(addr, group.IsRdma); err != nil {
+		return err
+	}
+
+	switch {
+	case sriovnetworkv1.StringInArray(group.DeviceType, vars.VdpaDrivers):
+		// moving a VF into vdpa mode first takes it back to its default kernel driver, since the
+		// vdpa device is created on top of it, then layers the vdpa device + userspace driver on
+		if err := h.BindDefaultDriver(addr); err != nil {
+			log.Log.Error(err, "configSriovDevice(): fail to bind default driver before vdpa setup", "device", addr)
+			return err
+		}
+		if err := h.EnsureVdpaDevice(addr, group.DeviceType); err != nil {
+			log.Log.Error(err, "configSriovDevice(): fail to configure vdpa device for device",
+				"driver", group.DeviceType, "device", addr)
+			return err
+		}
+	case !sriovnetworkv1.StringInArray(group.DeviceType, vars.DpdkDrivers):
+		if err := h.RemoveVdpaDevice(addr); err != nil {
+			log.Log.Error(err, "configSriovDevice(): fail to remove stale vdpa device for device", "device", addr)
+			return err
+		}
+		if err := h.BindDefaultDriver(addr); err != nil {
+			log.Log.Error(err, "configSriovDevice(): fail to bind default driver for device", "device", addr)
+			return err
+		}
+		// re-assert the VF's address now that its netdev is back, in case it came up with
+		// whatever a prior vfio-pci/uio_pci_generic stint left it with
+		if err := h.EnsureVFAddress(iface.PciAddress, addr, linkType); err != nil {
+			log.Log.Error(err, "configSriovDevice(): failed to ensure VF address", "device", addr)
+			return err
+		}
+		// only set MTU for VF with default driver
+		if group.Mtu > 0 {
+			if err := h.SetNetdevMTU(addr, group.Mtu); err != nil {
+				log.Log.Error(err, "configSriovDevice(): fail to set mtu for VF", "address", addr)
+				return err
+			}
+		}
+	default:
+		if err := h.RemoveVdpaDevice(addr); err != nil {
+			log.Log.Error(err, "configSriovDevice(): fail to remove stale vdpa device for device", "device", addr)
+			return err
+		}
+		// capture the VF's current MAC/GUID from its netdev before BindDpdkDriver unbinds it from
+		// its kernel driver, so the address survives the switch instead of coming up random
+		if yes, d := h.HasDriver(addr); yes && !sriovnetworkv1.StringInArray(d, vars.DpdkDrivers) {
+			if err := h.EnsureVFAddress(iface.PciAddress, addr, linkType); err != nil {
+				log.Log.Error(err, "configSriovDevice(): failed to preserve VF address before DPDK bind", "device", addr)
+				return err
+			}
+		}
+		if err := h.BindDpdkDriver(addr, group.DeviceType); err != nil {
+			log.Log.Error(err, "configSriovDevice(): fail to bind driver for device",
+				"driver", group.DeviceType, "device", addr)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncVfRepresentorUdevRule keeps the VF representor naming udev rule (see
+// AddVfRepresentorUdevRule) in sync with iface's current eswitch mode: present for PFs in
+// switchdev mode, removed for PFs in legacy mode or no eswitch mode at all.
+func (h *HostManager) syncVfRepresentorUdevRule(iface *sriovnetworkv1.Interface) error {
+	if iface.EswitchMode != sriovnetworkv1.ESwithModeSwitchDev {
+		return h.RemoveVfRepresentorUdevRule(iface.PciAddress)
+	}
+
+	switchID, err := h.GetPhysSwitchID(iface.Name)
+	if err != nil || switchID == "" {
+		// not switchdev-capable (or not yet visible) at the kernel level; nothing to rename yet
+		return nil
+	}
+	portName, err := h.GetPhysPortName(iface.Name)
+	if err != nil {
+		return err
+	}
+	pfNum := vars.PfPhysPortNameRe.FindString(portName)
+	if pfNum == "" {
+		return fmt.Errorf("syncVfRepresentorUdevRule(): PF %s has unexpected phys_port_name %q", iface.Name, portName)
+	}
+
+	return h.AddVfRepresentorUdevRule(iface.PciAddress, iface.Name, switchID, strings.TrimPrefix(pfNum, "p"))
+}
+
 func (h *HostManager) ConfigSriovDevice(iface *sriovnetworkv1.Interface, ifaceStatus *sriovnetworkv1.InterfaceExt) error {
 	log.Log.V(2).Info("configSriovDevice(): configure sriov device",
 		"device", iface.PciAddress, "config", iface)
@@ -163,6 +473,29 @@ func (h *HostManager) ConfigSriovDevice(iface *sriovnetworkv1.Interface, ifaceSt
 		log.Log.Error(err, "configSriovDevice(): fail to set NumVfs for device", "device", iface.PciAddress)
 		return err
 	}
+	// set eswitch mode if it drifted from the spec, e.g. an admin flipped it at the kernel level.
+	// Externally managed PFs are provisioned by something else (DPU firmware, ignition, ...), so
+	// the daemon must never write it here.
+	if iface.EswitchMode != "" && !iface.ExternallyManaged {
+		if mode, modeErr := h.GetNicSriovMode(iface.PciAddress); modeErr == nil && mode != "" && mode != iface.EswitchMode {
+			log.Log.Info("configSriovDevice(): eswitch mode drifted, reapplying", "device", iface.PciAddress, "want", iface.EswitchMode, "have", mode)
+			if err = h.SetNicSriovMode(iface.PciAddress, iface.EswitchMode); err != nil {
+				log.Log.Error(err, "configSriovDevice(): fail to set eswitch mode for device", "device", iface.PciAddress)
+				return err
+			}
+		}
+	}
+	if err = h.syncVfRepresentorUdevRule(iface); err != nil {
+		log.Log.Error(err, "configSriovDevice(): fail to sync VF representor udev rule for device", "device", iface.PciAddress)
+		return err
+	}
+	// Keep the NM-unmanaged udev rule in sync with ExternallyManaged regardless of whether NumVfs
+	// changed, so a PF that transitions to externally managed doesn't keep a stale rule around.
+	if err = h.AddUdevRule(iface.PciAddress, iface.ExternallyManaged); err != nil {
+		log.Log.Error(err, "configSriovDevice(): fail to sync NM-unmanaged udev rule for device", "device", iface.PciAddress)
+		return err
+	}
+
 	// set numVFs
 	if iface.NumVfs != ifaceStatus.NumVfs {
 		if iface.ExternallyManaged {
@@ -172,12 +505,6 @@ func (h *HostManager) ConfigSriovDevice(iface *sriovnetworkv1.Interface, ifaceSt
 				return fmt.Errorf(errMsg)
 			}
 		} else {
-			// create the udev rule to disable all the vfs from network manager as this vfs are managed by the operator
-			err = h.AddUdevRule(iface.PciAddress)
-			if err != nil {
-				return err
-			}
-
 			err = h.SetSriovNumVfs(iface.PciAddress, iface.NumVfs)
 			if err != nil {
 				log.Log.Error(err, "configSriovDevice(): fail to set NumVfs for device", "device", iface.PciAddress)
@@ -190,7 +517,7 @@ func (h *HostManager) ConfigSriovDevice(iface *sriovnetworkv1.Interface, ifaceSt
 		}
 	}
 	// set PF mtu
-	if iface.Mtu > 0 && iface.Mtu > ifaceStatus.Mtu {
+	if iface.Mtu > 0 && iface.Mtu > ifaceStatus.Mtu && !iface.ExternallyManaged {
 		err = h.SetNetdevMTU(iface.PciAddress, iface.Mtu)
 		if err != nil {
 			log.Log.Error(err, "configSriovDevice(): fail to set mtu for PF", "device", iface.PciAddress)
@@ -199,7 +526,7 @@ func (h *HostManager) ConfigSriovDevice(iface *sriovnetworkv1.Interface, ifaceSt
 	}
 	// Config VFs
 	if iface.NumVfs > 0 {
-		vfAddrs, err := dputils.GetVFList(iface.PciAddress)
+		vfAddrs, err := dpUtils.GetVFList(iface.PciAddress)
 		if err != nil {
 			log.Log.Error(err, "configSriovDevice(): unable to parse VFs for device", "device", iface.PciAddress)
 		}
@@ -209,190 +536,291 @@ func (h *HostManager) ConfigSriovDevice(iface *sriovnetworkv1.Interface, ifaceSt
 			return err
 		}
 
+		// VFs of the same PF touch independent sysfs/netlink state (SetVfAdminMac/BindDefaultDriver
+		// on one VF don't affect another), so they're configured through a bounded worker pool;
+		// VFIsReady's poll loop is what makes this worth parallelizing.
+		vg := new(errgroup.Group)
+		vg.SetLimit(vfConfigConcurrency)
 		for _, addr := range vfAddrs {
-			var group *sriovnetworkv1.VfGroup
-
-			vfID, err := dputils.GetVFID(addr)
+			addr := addr
+			vg.Go(func() error {
+				return h.configureVF(iface, ifaceStatus, pfLink, addr)
+			})
+		}
+		if err := vg.Wait(); err != nil {
+			return err
+		}
+	}
+	// Set PF link up
+	if !iface.ExternallyManaged {
+		pfLink, err := netlink.LinkByName(ifaceStatus.Name)
+		if err != nil {
+			return err
+		}
+		if pfLink.Attrs().OperState != netlink.OperUp {
+			err = netlink.LinkSetUp(pfLink)
 			if err != nil {
-				log.Log.Error(err, "configSriovDevice(): unable to get VF id", "device", iface.PciAddress)
 				return err
 			}
+		}
+	}
 
-			for i := range iface.VfGroups {
-				if sriovnetworkv1.IndexInRange(vfID, iface.VfGroups[i].VfRange) {
-					group = &iface.VfGroups[i]
-					break
-				}
-			}
-
-			// VF group not found.
-			if group == nil {
-				continue
-			}
-
-			// only set GUID and MAC for VF with default driver
-			// for userspace drivers like vfio we configure the vf mac using the kernel nic mac address
-			// before we switch to the userspace driver
-			if yes, d := h.HasDriver(addr); yes && !sriovnetworkv1.StringInArray(d, vars.DpdkDrivers) {
-				// LinkType is an optional field. Let's fallback to current link type
-				// if nothing is specified in the SriovNodePolicy
-				linkType := iface.LinkType
-				if linkType == "" {
-					linkType = ifaceStatus.LinkType
-				}
-				if strings.EqualFold(linkType, consts.LinkTypeIB) {
-					if err = h.SetVfGUID(addr, pfLink); err != nil {
-						return err
-					}
-				} else {
-					vfLink, err := h.VFIsReady(addr)
-					if err != nil {
-						log.Log.Error(err, "configSriovDevice(): VF link is not ready", "address", addr)
-						err = h.RebindVfToDefaultDriver(addr)
-						if err != nil {
-							log.Log.Error(err, "configSriovDevice(): failed to rebind VF", "address", addr)
-							return err
-						}
-
-						// Try to check the VF status again
-						vfLink, err = h.VFIsReady(addr)
-						if err != nil {
-							log.Log.Error(err, "configSriovDevice(): VF link is not ready", "address", addr)
-							return err
-						}
-					}
-					if err = h.SetVfAdminMac(addr, pfLink, vfLink); err != nil {
-						log.Log.Error(err, "configSriovDevice(): fail to configure VF admin mac", "device", addr)
-						return err
-					}
-				}
-			}
+	if err := h.WriteVfConfigUdevRules(iface); err != nil {
+		log.Log.Error(err, "configSriovDevice(): fail to sync VF config udev rules for device", "device", iface.PciAddress)
+		return err
+	}
+	return nil
+}
 
-			if err = h.UnbindDriverIfNeeded(addr, group.IsRdma); err != nil {
-				return err
-			}
+// WriteVfConfigUdevRules persists the current administrative MAC, VLAN, trust, spoofchk and
+// link-state of each of iface's VFs (as tracked by the kernel on the PF, see netlink.VfInfo) into
+// a per-PF udev rule file, so a VF re-appearing after a PF re-bind or node reboot gets that
+// configuration re-applied by sriov-vf-config.sh without the config-daemon needing to be running.
+// Switchdev PFs skip this entirely: their VF representors are renamed by AddVfRepresentorUdevRule
+// instead.
+func (h *HostManager) WriteVfConfigUdevRules(iface *sriovnetworkv1.Interface) error {
+	if iface.EswitchMode == sriovnetworkv1.ESwithModeSwitchDev || len(iface.VfGroups) == 0 {
+		return h.RemoveVfConfigUdevRules(iface.PciAddress)
+	}
 
-			if !sriovnetworkv1.StringInArray(group.DeviceType, vars.DpdkDrivers) {
-				if err := h.BindDefaultDriver(addr); err != nil {
-					log.Log.Error(err, "configSriovDevice(): fail to bind default driver for device", "device", addr)
-					return err
-				}
-				// only set MTU for VF with default driver
-				if group.Mtu > 0 {
-					if err := h.SetNetdevMTU(addr, group.Mtu); err != nil {
-						log.Log.Error(err, "configSriovDevice(): fail to set mtu for VF", "address", addr)
-						return err
-					}
-				}
-			} else {
-				if err := h.BindDpdkDriver(addr, group.DeviceType); err != nil {
-					log.Log.Error(err, "configSriovDevice(): fail to bind driver for device",
-						"driver", group.DeviceType, "device", addr)
-					return err
-				}
-			}
-		}
+	pfLink, err := netlink.LinkByName(iface.Name)
+	if err != nil {
+		log.Log.Error(err, "WriteVfConfigUdevRules(): unable to get PF link", "device", iface.PciAddress)
+		return err
 	}
-	// Set PF link up
-	pfLink, err := netlink.LinkByName(ifaceStatus.Name)
+	vfInfoByID := make(map[int]netlink.VfInfo, len(pfLink.Attrs().Vfs))
+	for _, vfInfo := range pfLink.Attrs().Vfs {
+		vfInfoByID[vfInfo.ID] = vfInfo
+	}
+
+	vfAddrs, err := dpUtils.GetVFList(iface.PciAddress)
 	if err != nil {
+		log.Log.Error(err, "WriteVfConfigUdevRules(): unable to list VFs for device", "device", iface.PciAddress)
 		return err
 	}
-	if pfLink.Attrs().OperState != netlink.OperUp {
-		err = netlink.LinkSetUp(pfLink)
+
+	var ruleLines []string
+	for _, vfAddr := range vfAddrs {
+		vfID, err := dpUtils.GetVFID(vfAddr)
 		if err != nil {
+			log.Log.Error(err, "WriteVfConfigUdevRules(): unable to get VF id", "address", vfAddr)
 			return err
 		}
+		vfInfo, ok := vfInfoByID[vfID]
+		if !ok {
+			continue
+		}
+		driverExist, driverName := h.HasDriver(vfAddr)
+		if !driverExist {
+			// nothing bound yet for this VF; it picks up its udev rule on the next sync once it has a driver
+			continue
+		}
+		ruleLines = append(ruleLines, fmt.Sprintf(vfConfigUdevRuleLine,
+			driverName, vfAddr,
+			consts.VfConfigUdevScript, vfAddr, vfInfo.Mac.String(), strconv.Itoa(vfInfo.Vlan),
+			vfLinkStateString(vfInfo.LinkState), boolOnOff(vfInfo.Trust), boolOnOff(vfInfo.Spoofchk)))
 	}
-	return nil
+
+	return h.writeVfConfigUdevRuleFile(iface.PciAddress, ruleLines)
 }
 
-func (h *HostManager) ConfigSriovInterfaces(storeManager StoreManagerInterface, interfaces []sriovnetworkv1.Interface, ifaceStatuses []sriovnetworkv1.InterfaceExt, pfsToConfig map[string]bool) error {
-	if h.IsKernelLockdownMode() && mlx.HasMellanoxInterfacesInSpec(ifaceStatuses, interfaces) {
-		log.Log.Error(nil, "cannot use mellanox devices when in kernel lockdown mode")
-		return fmt.Errorf("cannot use mellanox devices when in kernel lockdown mode")
+// vfLinkStateString renders a netlink VfInfo.LinkState (IFLA_VF_LINK_STATE_AUTO/ENABLE/DISABLE)
+// as the argument sriov-vf-config.sh passes to `ip link set ... state <state>`.
+func vfLinkStateString(state uint32) string {
+	switch state {
+	case 1:
+		return "enable"
+	case 2:
+		return "disable"
+	default:
+		return "auto"
 	}
+}
 
-	for _, ifaceStatus := range ifaceStatuses {
-		configured := false
-		for _, iface := range interfaces {
-			if iface.PciAddress == ifaceStatus.PciAddress {
-				configured = true
+func boolOnOff(v bool) string {
+	if v {
+		return "on"
+	}
+	return "off"
+}
 
-				if skip := pfsToConfig[iface.PciAddress]; skip {
-					break
-				}
+// configSwitchdevVfAdminMac programs the VF admin MAC via the PF for a VF that is about to be
+// bound to a userspace driver (vfio-pci) on a switchdev PF. The kernel can only set a VF's admin
+// MAC while the VF itself still carries a kernel driver, so this temporarily binds the VF to its
+// default driver, applies the MAC (and MTU, if the group requests one) over netlink, then waits for
+// the VF's representor netdev to appear before returning. The caller's existing
+// UnbindDriverIfNeeded/BindDpdkDriver sequence takes it from there to move the VF onto its
+// userspace driver.
+func (h *HostManager) configSwitchdevVfAdminMac(addr string, vfID int, pfLink netlink.Link, iface *sriovnetworkv1.Interface, group *sriovnetworkv1.VfGroup) error {
+	if err := h.BindDefaultDriver(addr); err != nil {
+		log.Log.Error(err, "configSwitchdevVfAdminMac(): fail to bind default driver for device", "device", addr)
+		return err
+	}
 
-				if !sriovnetworkv1.NeedToUpdateSriov(&iface, &ifaceStatus) {
-					log.Log.V(2).Info("syncNodeState(): no need update interface", "address", iface.PciAddress)
+	vfLink, err := h.VFIsReady(addr)
+	if err != nil {
+		log.Log.Error(err, "configSwitchdevVfAdminMac(): VF link is not ready", "address", addr)
+		return err
+	}
 
-					// Save the PF status to the host
-					err := storeManager.SaveLastPfAppliedStatus(&iface)
-					if err != nil {
-						log.Log.Error(err, "SyncNodeState(): failed to save PF applied config to host")
-						return err
-					}
+	if err := h.SetVfAdminMac(addr, pfLink, vfLink); err != nil {
+		log.Log.Error(err, "configSwitchdevVfAdminMac(): fail to configure VF admin mac", "device", addr)
+		return err
+	}
 
-					break
-				}
-				if err := h.ConfigSriovDevice(&iface, &ifaceStatus); err != nil {
-					log.Log.Error(err, "SyncNodeState(): fail to configure sriov interface. resetting interface.", "address", iface.PciAddress)
-					if iface.ExternallyManaged {
-						log.Log.Info("SyncNodeState(): skipping device reset as the nic is marked as externally created")
-					} else {
-						if resetErr := h.ResetSriovDevice(ifaceStatus); resetErr != nil {
-							log.Log.Error(resetErr, "SyncNodeState(): failed to reset on error SR-IOV interface")
-						}
-					}
-					return err
-				}
+	if group.Mtu > 0 {
+		if err := h.SetNetdevMTU(addr, group.Mtu); err != nil {
+			log.Log.Error(err, "configSwitchdevVfAdminMac(): fail to set mtu for VF", "address", addr)
+			return err
+		}
+	}
 
-				// Save the PF status to the host
-				err := storeManager.SaveLastPfAppliedStatus(&iface)
-				if err != nil {
-					log.Log.Error(err, "SyncNodeState(): failed to save PF applied config to host")
-					return err
-				}
-				break
-			}
+	if err := wait.PollImmediate(500*time.Millisecond, 10*time.Second, func() (bool, error) {
+		representor, err := h.GetVfRepresentor(iface.Name, vfID)
+		return representor != "", err
+	}); err != nil {
+		log.Log.Error(err, "configSwitchdevVfAdminMac(): timed out waiting for VF representor", "device", addr)
+		return err
+	}
+
+	return nil
+}
+
+// pfConfigConcurrency bounds how many PFs ConfigSriovInterfaces configures at once. VFIsReady's
+// poll loop (up to 10s per VF) dominates reconciliation time on nodes with many PFs, so PFs are
+// fanned out instead of walked serially; the bound keeps a node with dozens of PFs from opening
+// an unbounded number of goroutines/file descriptors at once.
+const pfConfigConcurrency = 8
+
+// pfsInFlight guards against two concurrent configurePFLocked calls for the same PF PCI address.
+// ConfigSriovInterfaces itself only ever issues one call per PF per invocation, so in normal
+// operation this never contends. A direct ConfigSriovDevice call does not consult it.
+var pfsInFlight sync.Map
+
+// configurePFLocked configures or resets the single PF described by ifaceStatus. If another call
+// for the same PCI address is already in flight (tracked in pfsInFlight), it returns an error
+// without waiting for that call or configuring anything.
+func (h *HostManager) configurePFLocked(storeManager StoreManagerInterface, ifaceStatus sriovnetworkv1.InterfaceExt, iface sriovnetworkv1.Interface, configured bool, pfsToConfig map[string]bool) error {
+	if _, alreadyInFlight := pfsInFlight.LoadOrStore(ifaceStatus.PciAddress, true); alreadyInFlight {
+		return fmt.Errorf("configurePFLocked(): device %s is already being configured", ifaceStatus.PciAddress)
+	}
+	defer pfsInFlight.Delete(ifaceStatus.PciAddress)
+
+	return h.configurePF(storeManager, ifaceStatus, iface, configured, pfsToConfig)
+}
+
+// configurePF holds the per-PF body that used to live inline in ConfigSriovInterfaces' loop: it
+// either brings the PF in line with its Interface spec, or - if the PF has no spec anymore but
+// still has VFs from a previous sync - resets it.
+func (h *HostManager) configurePF(storeManager StoreManagerInterface, ifaceStatus sriovnetworkv1.InterfaceExt, iface sriovnetworkv1.Interface, configured bool, pfsToConfig map[string]bool) error {
+	if configured {
+		if skip := pfsToConfig[iface.PciAddress]; skip {
+			return nil
 		}
-		if !configured && ifaceStatus.NumVfs > 0 {
-			if skip := pfsToConfig[ifaceStatus.PciAddress]; skip {
-				continue
-			}
 
-			// load the PF info
-			pfStatus, exist, err := storeManager.LoadPfsStatus(ifaceStatus.PciAddress)
-			if err != nil {
-				log.Log.Error(err, "SyncNodeState(): failed to load info about PF status for device",
-					"address", ifaceStatus.PciAddress)
-				return err
-			}
+		if !sriovnetworkv1.NeedToUpdateSriov(&iface, &ifaceStatus) && !h.HasOutOfBandDrift(&iface, &ifaceStatus) {
+			log.Log.V(2).Info("syncNodeState(): no need update interface", "address", iface.PciAddress)
 
-			if !exist {
-				log.Log.Info("SyncNodeState(): PF name with pci address has VFs configured but they weren't created by the sriov operator. Skipping the device reset",
-					"pf-name", ifaceStatus.Name,
-					"address", ifaceStatus.PciAddress)
-				continue
+			// Save the PF status to the host
+			if err := storeManager.SaveLastPfAppliedStatus(&iface); err != nil {
+				log.Log.Error(err, "SyncNodeState(): failed to save PF applied config to host")
+				return err
 			}
+			return nil
+		}
 
-			if pfStatus.ExternallyManaged {
-				log.Log.Info("SyncNodeState(): PF name with pci address was externally created skipping the device reset",
-					"pf-name", ifaceStatus.Name,
-					"address", ifaceStatus.PciAddress)
-				continue
+		if err := h.ConfigSriovDevice(&iface, &ifaceStatus); err != nil {
+			log.Log.Error(err, "SyncNodeState(): fail to configure sriov interface. resetting interface.", "address", iface.PciAddress)
+			if iface.ExternallyManaged {
+				log.Log.Info("SyncNodeState(): skipping device reset as the nic is marked as externally created")
 			} else {
-				err = h.RemoveUdevRule(ifaceStatus.PciAddress)
-				if err != nil {
-					return err
+				if resetErr := h.ResetSriovDevice(ifaceStatus); resetErr != nil {
+					log.Log.Error(resetErr, "SyncNodeState(): failed to reset on error SR-IOV interface")
 				}
 			}
+			return err
+		}
 
-			if err = h.ResetSriovDevice(ifaceStatus); err != nil {
-				return err
-			}
+		// Save the PF status to the host
+		if err := storeManager.SaveLastPfAppliedStatus(&iface); err != nil {
+			log.Log.Error(err, "SyncNodeState(): failed to save PF applied config to host")
+			return err
+		}
+		return nil
+	}
+
+	if ifaceStatus.NumVfs == 0 {
+		return nil
+	}
+	if skip := pfsToConfig[ifaceStatus.PciAddress]; skip {
+		return nil
+	}
+
+	// load the PF info
+	pfStatus, exist, err := storeManager.LoadPfsStatus(ifaceStatus.PciAddress)
+	if err != nil {
+		log.Log.Error(err, "SyncNodeState(): failed to load info about PF status for device",
+			"address", ifaceStatus.PciAddress)
+		return err
+	}
+
+	if !exist {
+		log.Log.Info("SyncNodeState(): PF name with pci address has VFs configured but they weren't created by the sriov operator. Skipping the device reset",
+			"pf-name", ifaceStatus.Name,
+			"address", ifaceStatus.PciAddress)
+		return nil
+	}
+
+	if pfStatus.ExternallyManaged {
+		log.Log.Info("SyncNodeState(): PF name with pci address was externally created skipping the device reset",
+			"pf-name", ifaceStatus.Name,
+			"address", ifaceStatus.PciAddress)
+		return nil
+	}
+	if err = h.RemoveUdevRule(ifaceStatus.PciAddress); err != nil {
+		return err
+	}
+
+	return h.ResetSriovDevice(ifaceStatus)
+}
+
+func (h *HostManager) ConfigSriovInterfaces(storeManager StoreManagerInterface, interfaces []sriovnetworkv1.Interface, ifaceStatuses []sriovnetworkv1.InterfaceExt, pfsToConfig map[string]bool) error {
+	kernelLockdownMode := h.IsKernelLockdownMode()
+	for _, handler := range vendors.ForInterfaces(ifaceStatuses) {
+		if err := handler.PreConfig(ifaceStatuses, interfaces, kernelLockdownMode); err != nil {
+			log.Log.Error(err, "ConfigSriovInterfaces(): vendor handler rejected PreConfig")
+			return err
+		}
+	}
+
+	ifaceByAddress := make(map[string]sriovnetworkv1.Interface, len(interfaces))
+	for _, iface := range interfaces {
+		ifaceByAddress[iface.PciAddress] = iface
+	}
+
+	// Each PF touches disjoint sysfs paths and netlink indices, so PFs are configured concurrently,
+	// bounded to pfConfigConcurrency in-flight at a time. configurePFLocked still guards each PF
+	// individually via pfsInFlight in case ConfigSriovInterfaces is ever called re-entrantly.
+	g := new(errgroup.Group)
+	g.SetLimit(pfConfigConcurrency)
+	for i := range ifaceStatuses {
+		ifaceStatus := ifaceStatuses[i]
+		g.Go(func() error {
+			iface, configured := ifaceByAddress[ifaceStatus.PciAddress]
+			return h.configurePFLocked(storeManager, ifaceStatus, iface, configured, pfsToConfig)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	for _, handler := range vendors.ForInterfaces(ifaceStatuses) {
+		if err := handler.PostConfig(ifaceStatuses, interfaces); err != nil {
+			log.Log.Error(err, "ConfigSriovInterfaces(): vendor handler rejected PostConfig")
+			return err
 		}
 	}
+
 	return nil
 }
 
@@ -0,0 +1,148 @@
+package host
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sync/errgroup"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/vars"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/dputils"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/sysfs"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/vdpa"
+)
+
+// benchDriver is the DPDK driver synthetic VFs are bound to; it drives the same
+// BindDriverByBusAndDevice codepath configureVF's default switch case uses for any of
+// vars.DpdkDrivers.
+const benchDriver = "vfio-pci"
+
+// noopVdpaManager satisfies vdpa.VdpaManager without touching real vdpa netlink, standing in for
+// newVdpaManager in the benchmark below. configureVF's default case (DeviceType == benchDriver,
+// a DPDK driver) only ever calls RemoveVdpaDevice to clear a stale vdpa device - there is none on
+// a fresh synthetic VF, so returning "no device" is the real, not special-cased, behavior.
+type noopVdpaManager struct{}
+
+func (noopVdpaManager) EnsureVdpaDevice(pciAddr, driver string) error { return nil }
+func (noopVdpaManager) RemoveVdpaDevice(pciAddr string) error         { return nil }
+func (noopVdpaManager) GetVdpaDeviceName(pciAddr string) (string, error) {
+	return "", nil
+}
+
+// benchDPUtils stands in for dpUtils in the benchmark below: every synthetic VF address resolves
+// to its VF index, and GetDriverName reports no driver bound, the fresh-node case.
+type benchDPUtils struct {
+	dputils.DPUtilsLib
+	vfIDs map[string]int
+}
+
+func (f benchDPUtils) GetVFID(pciAddr string) (int, error) { return f.vfIDs[pciAddr], nil }
+func (f benchDPUtils) GetDriverName(pciAddr string) (string, error) {
+	return "", fmt.Errorf("no driver bound to %s", pciAddr)
+}
+
+func syntheticVFAddrs(n int) []string {
+	addrs := make([]string, n)
+	for i := range addrs {
+		addrs[i] = fmt.Sprintf("0000:3b:%02x.0", i)
+	}
+	return addrs
+}
+
+// benchIface and its single VfGroup cover every synthetic VF with benchDriver, a DPDK driver, so
+// configureVF skips its kernel-driver MAC/GUID step (HasDriver is false on a fresh node) and takes
+// its default case: RemoveVdpaDevice (faked above), then the real BindDpdkDriver/BindDriverByBusAndDevice.
+func benchIface() *sriovnetworkv1.Interface {
+	return &sriovnetworkv1.Interface{
+		PciAddress: "0000:3b:00.0",
+		Name:       "bench0",
+		NumVfs:     128,
+		VfGroups: []sriovnetworkv1.VfGroup{{
+			DeviceType:   benchDriver,
+			PolicyName:   "bench-policy",
+			ResourceName: "bench-resource",
+			VfRange:      "0-127",
+		}},
+	}
+}
+
+func benchIfaceStatus() *sriovnetworkv1.InterfaceExt {
+	return &sriovnetworkv1.InterfaceExt{
+		Name:       "bench0",
+		PciAddress: "0000:3b:00.0",
+	}
+}
+
+// setupBenchSysfsFS points vars.FilesystemRoot at a scratch directory (so GetPhysSwitchID's and
+// HasDriverByBusAndDevice's reads see "nothing here yet" for every synthetic address and PF, the
+// fresh-node case) and swaps the package-level dpUtils, writer and vdpa manager for fakes, so
+// configureVF's real sysfs writes run without touching the host or real vdpa netlink.
+func setupBenchSysfsFS(b *testing.B) {
+	vars.FilesystemRoot = b.TempDir()
+	origDPUtils := dpUtils
+	vfIDs := map[string]int{}
+	for i, addr := range syntheticVFAddrs(benchIface().NumVfs) {
+		vfIDs[addr] = i
+	}
+	dpUtils = benchDPUtils{vfIDs: vfIDs}
+	origWriter := writer
+	writer = sysfs.NewFake(afero.NewMemMapFs())
+	origVdpaManager := newVdpaManager
+	newVdpaManager = func(*HostManager) vdpa.VdpaManager { return noopVdpaManager{} }
+	b.Cleanup(func() {
+		vars.FilesystemRoot = ""
+		dpUtils = origDPUtils
+		writer = origWriter
+		newVdpaManager = origVdpaManager
+	})
+}
+
+// BenchmarkConfigureVFSerial models the old per-PF VF loop: one VF configured at a time. It
+// drives the real configureVF - the same call ConfigSriovDevice makes for every VF - over a
+// synthetic 128-VF PF all headed to a DPDK driver.
+func BenchmarkConfigureVFSerial(b *testing.B) {
+	setupBenchSysfsFS(b)
+	h := &HostManager{}
+	iface := benchIface()
+	ifaceStatus := benchIfaceStatus()
+	pfLink := &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: iface.Name}}
+	addrs := syntheticVFAddrs(iface.NumVfs)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, addr := range addrs {
+			if err := h.configureVF(iface, ifaceStatus, pfLink, addr); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkConfigureVFPooled models ConfigSriovDevice's current bounded worker pool
+// (vfConfigConcurrency in-flight VFs) driving the same real configureVF calls.
+func BenchmarkConfigureVFPooled(b *testing.B) {
+	setupBenchSysfsFS(b)
+	h := &HostManager{}
+	iface := benchIface()
+	ifaceStatus := benchIfaceStatus()
+	pfLink := &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: iface.Name}}
+	addrs := syntheticVFAddrs(iface.NumVfs)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g := new(errgroup.Group)
+		g.SetLimit(vfConfigConcurrency)
+		for _, addr := range addrs {
+			addr := addr
+			g.Go(func() error {
+				return h.configureVF(iface, ifaceStatus, pfLink, addr)
+			})
+		}
+		if err := g.Wait(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/vars"
+)
+
+func TestIsZeroGUID(t *testing.T) {
+	assert.True(t, isZeroGUID("0000:0000:0000:0000"))
+	assert.True(t, isZeroGUID("00:00:00:00:00:00:00:00"))
+	assert.False(t, isZeroGUID("0002:c903:0003:97f1"))
+	assert.False(t, isZeroGUID(""))
+}
+
+func TestParseIBGUID(t *testing.T) {
+	hwAddr, err := parseIBGUID("0002:c903:0003:97f1")
+	require.NoError(t, err)
+	assert.Equal(t, "00:02:c9:03:00:03:97:f1", hwAddr.String())
+
+	_, err = parseIBGUID("not-a-guid")
+	assert.Error(t, err)
+}
+
+func TestSRIOVCapable(t *testing.T) {
+	setupSysfsTestFS(t)
+	h := &HostManager{}
+
+	capable, err := h.SRIOVCapable("0000:3b:00.0")
+	require.NoError(t, err)
+	assert.False(t, capable)
+
+	pfDir := filepath.Join(vars.FilesystemRoot, consts.SysBusPciDevices, "0000:3b:00.0")
+	require.NoError(t, os.MkdirAll(pfDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pfDir, consts.NumVfsFile), []byte("0"), 0644))
+
+	capable, err = h.SRIOVCapable("0000:3b:00.0")
+	require.NoError(t, err)
+	assert.True(t, capable)
+}
+
+func TestSetSriovNumVfsUnsupportedDevice(t *testing.T) {
+	setupSysfsTestFS(t)
+	h := &HostManager{}
+
+	err := h.SetSriovNumVfs("0000:3b:00.0", 4)
+	assert.ErrorIs(t, err, ErrSRIOVUnsupported)
+}
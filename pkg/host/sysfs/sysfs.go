@@ -0,0 +1,33 @@
+// Package sysfs abstracts writes to sysfs control files (bind, unbind, driver_override,
+// drivers_probe, ...) behind an afero.Fs, so the driver-binding code in pkg/host can be unit
+// tested against an in-memory filesystem instead of mutating a real /sys.
+package sysfs
+
+import (
+	"github.com/spf13/afero"
+)
+
+// Writer writes a sysfs control file. Unlike a plain os.WriteFile, a real sysfs control file
+// generally can't be read back or truncated the normal way, so the interface only exposes the
+// single operation the driver-binding code actually needs.
+type Writer interface {
+	WriteFile(path string, data []byte) error
+}
+
+type fsWriter struct {
+	fs afero.Fs
+}
+
+// New returns a Writer backed by the real OS filesystem.
+func New() Writer {
+	return &fsWriter{fs: afero.NewOsFs()}
+}
+
+// NewFake returns a Writer backed by fs, normally an afero.NewMemMapFs(), for use in tests.
+func NewFake(fs afero.Fs) Writer {
+	return &fsWriter{fs: fs}
+}
+
+func (w *fsWriter) WriteFile(path string, data []byte) error {
+	return afero.WriteFile(w.fs, path, data, 0644)
+}
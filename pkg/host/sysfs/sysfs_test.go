@@ -0,0 +1,20 @@
+package sysfs
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeWriterWritesToMemMapFs(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	w := NewFake(fs)
+
+	require.NoError(t, w.WriteFile("/sys/bus/pci/drivers/vfio-pci/bind", []byte("0000:3b:00.0")))
+
+	data, err := afero.ReadFile(fs, "/sys/bus/pci/drivers/vfio-pci/bind")
+	require.NoError(t, err)
+	assert.Equal(t, "0000:3b:00.0", string(data))
+}
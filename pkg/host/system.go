@@ -0,0 +1,62 @@
+package host
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/vars"
+)
+
+// GetRDMASubsystemMode returns the kernel RDMA subsystem network-namespace mode, either
+// consts.RdmaSubsystemModeShared or consts.RdmaSubsystemModeExclusive. It reads the mode through
+// an RDMA_NLDEV_CMD_SYS_GET netlink request (see rdma_netlink.go) instead of shelling out to the
+// `rdma` binary, which isn't guaranteed to exist in the operator's container image.
+func (h *HostManager) GetRDMASubsystemMode() (string, error) {
+	log.Log.V(2).Info("GetRDMASubsystemMode()")
+
+	netnsMode, err := getRDMASubsystemMode()
+	if err != nil {
+		log.Log.Error(err, "GetRDMASubsystemMode(): failed to get RDMA subsystem mode")
+		return "", err
+	}
+
+	if netnsMode == rdmaNetnsModeExclusive {
+		return consts.RdmaSubsystemModeExclusive, nil
+	}
+	return consts.RdmaSubsystemModeShared, nil
+}
+
+// SetRDMASubsystemMode configures the kernel RDMA subsystem network-namespace mode via an
+// RDMA_NLDEV_CMD_SYS_SET netlink request (see rdma_netlink.go). Switching modes requires every
+// RDMA user to be released first, so callers should treat this as a reboot-requiring change.
+//
+// This must only run before any non-init network namespace exists on the host, i.e. from the
+// systemd phasePre step of the config-daemon service rather than from the running daemon: once a
+// container or netns has been created, the kernel refuses the mode switch. It returns an error
+// without touching the mode when vars.UsingSystemdMode is false.
+func (h *HostManager) SetRDMASubsystemMode(mode string) error {
+	log.Log.Info("SetRDMASubsystemMode(): set RDMA subsystem mode", "mode", mode)
+
+	var netnsMode uint8
+	switch mode {
+	case consts.RdmaSubsystemModeExclusive:
+		netnsMode = rdmaNetnsModeExclusive
+	case consts.RdmaSubsystemModeShared:
+		netnsMode = rdmaNetnsModeShared
+	default:
+		return fmt.Errorf("SetRDMASubsystemMode(): unknown RDMA subsystem mode %q", mode)
+	}
+
+	if !vars.UsingSystemdMode {
+		return fmt.Errorf("SetRDMASubsystemMode(): refusing to change RDMA subsystem mode outside of " +
+			"the systemd phasePre step, since a non-init network namespace may already exist on the host")
+	}
+
+	if err := setRDMASubsystemMode(netnsMode); err != nil {
+		log.Log.Error(err, "SetRDMASubsystemMode(): failed to set RDMA subsystem mode", "mode", mode)
+		return err
+	}
+	return nil
+}
@@ -20,30 +20,11 @@ type config struct {
 	Interfaces []sriovnetworkv1.Interface `json:"interfaces"`
 }
 
-func (h *HostManager) PrepareNMUdevRule(supportedVfIds []string) error {
-	log.Log.V(2).Info("PrepareNMUdevRule()")
-	filePath := filepath.Join(vars.FilesystemRoot, consts.HostUdevRulesFolder, "10-nm-unmanaged.rules")
-
-	// remove the old unmanaged rules file
-	if _, err := os.Stat(filePath); err == nil {
-		err = os.Remove(filePath)
-		if err != nil {
-			log.Log.Error(err, "failed to remove the network manager global unmanaged rule",
-				"path", filePath)
-		}
-	}
-
-	// create the pf finder script for udev rules
-	stdout, stderr, err := h.utilsHelper.RunCommand("/bin/bash", filepath.Join(vars.FilesystemRoot, consts.UdevDisableNM))
-	if err != nil {
-		log.Log.Error(err, "PrepareNMUdevRule(): failed to prepare nmUdevRule", "stderr", stderr)
-		return err
-	}
-	log.Log.V(2).Info("PrepareNMUdevRule()", "stdout", stdout)
-
-	//save the device list to use for udev rules
-	vars.SupportedVfIds = supportedVfIds
-	return nil
+// PrepareNMUdevRule delegates to the detected NetworkManagerBackend (see
+// network_manager_backend.go) to perform whatever one-time setup that backend needs to keep its
+// hands off the VF IDs the operator manages.
+func (h *HostManager) PrepareNMUdevRule(supportedVfIds []string, managedPciAddresses []string) error {
+	return h.networkManagerBackend().Prepare(supportedVfIds, managedPciAddresses)
 }
 
 func (h *HostManager) WriteSwitchdevConfFile(newState *sriovnetworkv1.SriovNetworkNodeState, pfsToSkip map[string]bool) (update bool, err error) {
@@ -101,7 +82,7 @@ func (h *HostManager) WriteSwitchdevConfFile(newState *sriovnetworkv1.SriovNetwo
 			}
 
 			log.Log.V(2).Info("WriteSwitchdevConfFile(): file not existed, create it")
-			_, err = os.Create(consts.SriovHostSwitchDevConfPath)
+			err = atomicWriteFile(consts.SriovHostSwitchDevConfPath, []byte{}, 0644)
 			if err != nil {
 				log.Log.Error(err, "WriteSwitchdevConfFile(): failed to create file")
 				return
@@ -130,7 +111,7 @@ func (h *HostManager) WriteSwitchdevConfFile(newState *sriovnetworkv1.SriovNetwo
 	}
 	update = true
 	log.Log.V(2).Info("WriteSwitchdevConfFile(): write to switchdev.conf", "content", newContent)
-	err = os.WriteFile(consts.SriovHostSwitchDevConfPath, newContent, 0644)
+	err = atomicWriteFile(consts.SriovHostSwitchDevConfPath, newContent, 0644)
 	if err != nil {
 		log.Log.Error(err, "WriteSwitchdevConfFile(): failed to write file")
 		return
@@ -138,32 +119,99 @@ func (h *HostManager) WriteSwitchdevConfFile(newState *sriovnetworkv1.SriovNetwo
 	return
 }
 
-func (h *HostManager) AddUdevRule(pfPciAddress string) error {
-	log.Log.V(2).Info("AddUdevRule()", "device", pfPciAddress)
-	pathFile := filepath.Join(vars.FilesystemRoot, consts.UdevRulesFolder)
-	udevRuleContent := fmt.Sprintf(consts.NMUdevRule, strings.Join(vars.SupportedVfIds, "|"), pfPciAddress)
+// AddUdevRule takes pfPciAddress out of the active NetworkManagerBackend's management, unless
+// externallyManaged is true, in which case it removes any existing rule for the PF instead.
+func (h *HostManager) AddUdevRule(pfPciAddress string, externallyManaged bool) error {
+	backend := h.networkManagerBackend()
+	if externallyManaged {
+		// the PF is managed by the admin, not us: don't take it away from the host's network
+		// stack, and drop any rule a previous, non-externally-managed sync may have left behind.
+		log.Log.V(2).Info("AddUdevRule(): device is externally managed, removing any stale rule", "device", pfPciAddress, "backend", backend.Name())
+		return backend.ClearUnmanaged(pfPciAddress)
+	}
+	return backend.MarkUnmanaged(pfPciAddress)
+}
+
+// RemoveUdevRule returns pfPciAddress to the active NetworkManagerBackend's management.
+func (h *HostManager) RemoveUdevRule(pfPciAddress string) error {
+	return h.networkManagerBackend().ClearUnmanaged(pfPciAddress)
+}
 
+// switchdevVfRepresentorUdevRule is the NAME= udev rule that gives a switchdev PF's VF
+// representor netdevs stable, predictable names (<pfName>_<vf index>, e.g. enp3s0f0_0), matching
+// on the representor's phys_switch_id and its "pf<pfIndex>vf*" phys_port_name. It shells out to
+// switchdev-vf-link-name.sh (dropped on the host alongside udev-find-sriov-pf.sh) to turn the
+// representor's phys_port_name into the VF index used in NAME=.
+const switchdevVfRepresentorUdevRule = `SUBSYSTEM=="net", ACTION=="add|move", ATTRS{phys_switch_id}=="%s", ATTR{phys_port_name}=="pf%svf*", IMPORT{program}="%s $attr{phys_port_name}", NAME="%s_$env{NUMBER}"
+`
+
+// AddVfRepresentorUdevRule writes a per-PF udev rules file so the VF representor netdevs of a
+// switchdev-mode PF get stable names (<pfName>_0, <pfName>_1, ...) instead of the kernel's default
+// enumeration, which can change across reboots/PF re-binds.
+func (h *HostManager) AddVfRepresentorUdevRule(pfPciAddress, pfName, switchID, pfIndex string) error {
+	log.Log.V(2).Info("AddVfRepresentorUdevRule()", "device", pfPciAddress)
+	pathFile := filepath.Join(vars.FilesystemRoot, consts.HostUdevRulesFolder)
 	err := os.MkdirAll(pathFile, os.ModePerm)
 	if err != nil && !os.IsExist(err) {
-		log.Log.Error(err, "AddUdevRule(): failed to create dir", "path", pathFile)
+		log.Log.Error(err, "AddVfRepresentorUdevRule(): failed to create dir", "path", pathFile)
 		return err
 	}
 
-	filePath := path.Join(pathFile, fmt.Sprintf("10-nm-disable-%s.rules", pfPciAddress))
-	// if the file does not exist or if oldContent != newContent
-	// write to file and create it if it doesn't exist
-	err = os.WriteFile(filePath, []byte(udevRuleContent), 0666)
-	if err != nil {
-		log.Log.Error(err, "AddUdevRule(): fail to write file", "path", filePath)
+	udevRuleContent := fmt.Sprintf(switchdevVfRepresentorUdevRule, switchID, pfIndex, consts.SwitchdevVfLinkNameScript, pfName)
+	filePath := path.Join(pathFile, fmt.Sprintf("20-switchdev-%s.rules", pfPciAddress))
+	if err := atomicWriteFile(filePath, []byte(udevRuleContent), 0666); err != nil {
+		log.Log.Error(err, "AddVfRepresentorUdevRule(): fail to write file", "path", filePath)
 		return err
 	}
 	return nil
 }
 
-func (h *HostManager) RemoveUdevRule(pfPciAddress string) error {
-	pathFile := filepath.Join(vars.FilesystemRoot, consts.UdevRulesFolder)
-	filePath := path.Join(pathFile, fmt.Sprintf("10-nm-disable-%s.rules", pfPciAddress))
-	err := os.Remove(filePath)
+// RemoveVfRepresentorUdevRule removes the VF representor udev rule added by
+// AddVfRepresentorUdevRule, e.g. on eSwitch mode revert or PF removal.
+func (h *HostManager) RemoveVfRepresentorUdevRule(pfPciAddress string) error {
+	pathFile := filepath.Join(vars.FilesystemRoot, consts.HostUdevRulesFolder)
+	filePath := path.Join(pathFile, fmt.Sprintf("20-switchdev-%s.rules", pfPciAddress))
+	err := atomicRemoveFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// vfConfigUdevRuleLine is the udev RUN+= rule line for a single VF: it matches the VF's netdev by
+// driver and PCI address, then shells out to sriov-vf-config.sh (dropped on the host alongside the
+// other udev helper scripts) to re-apply the VF's administrative MAC, VLAN, link-state, trust and
+// spoofchk via `ip link set`.
+const vfConfigUdevRuleLine = `SUBSYSTEM=="net", ACTION=="add", ENV{ID_NET_DRIVER}=="%s", KERNELS=="%s", RUN+="%s %s %s %s %s %s %s"
+`
+
+// writeVfConfigUdevRuleFile writes ruleLines (see vfConfigUdevRuleLine) to the PF's udev rules
+// file, or removes the file if there are no VFs left to configure.
+func (h *HostManager) writeVfConfigUdevRuleFile(pfPciAddress string, ruleLines []string) error {
+	if len(ruleLines) == 0 {
+		return h.RemoveVfConfigUdevRules(pfPciAddress)
+	}
+
+	pathFile := filepath.Join(vars.FilesystemRoot, consts.HostUdevRulesFolder)
+	if err := os.MkdirAll(pathFile, os.ModePerm); err != nil && !os.IsExist(err) {
+		log.Log.Error(err, "WriteVfConfigUdevRules(): failed to create dir", "path", pathFile)
+		return err
+	}
+
+	filePath := path.Join(pathFile, fmt.Sprintf("30-sriov-vf-%s.rules", pfPciAddress))
+	if err := atomicWriteFile(filePath, []byte(strings.Join(ruleLines, "")), 0666); err != nil {
+		log.Log.Error(err, "WriteVfConfigUdevRules(): fail to write file", "path", filePath)
+		return err
+	}
+	return nil
+}
+
+// RemoveVfConfigUdevRules removes the per-VF udev rule file added by WriteVfConfigUdevRules, e.g.
+// on PF reset or when the PF moves to switchdev mode (VF representors are handled separately).
+func (h *HostManager) RemoveVfConfigUdevRules(pfPciAddress string) error {
+	pathFile := filepath.Join(vars.FilesystemRoot, consts.HostUdevRulesFolder)
+	filePath := path.Join(pathFile, fmt.Sprintf("30-sriov-vf-%s.rules", pfPciAddress))
+	err := atomicRemoveFile(filePath)
 	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
@@ -0,0 +1,28 @@
+package host
+
+import (
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/vdpa"
+)
+
+// newVdpaManager builds the VdpaManager used for h's vdpa calls below; it's a package-level var,
+// like writer and dpUtils in driver.go, so tests can swap in a fake VdpaManager instead of
+// driving real vdpa netlink via govdpa.
+var newVdpaManager = func(h *HostManager) vdpa.VdpaManager {
+	return vdpa.New(h)
+}
+
+// EnsureVdpaDevice makes sure a vdpa device exists on top of the VF at pciAddr and is bound to
+// driver ("vhost_vdpa" or "virtio_vdpa"), see vdpa.VdpaManager.EnsureVdpaDevice.
+func (h *HostManager) EnsureVdpaDevice(pciAddr, driver string) error {
+	return newVdpaManager(h).EnsureVdpaDevice(pciAddr, driver)
+}
+
+// RemoveVdpaDevice unbinds and deletes the vdpa device on top of the VF at pciAddr, if any.
+func (h *HostManager) RemoveVdpaDevice(pciAddr string) error {
+	return newVdpaManager(h).RemoveVdpaDevice(pciAddr)
+}
+
+// GetVdpaDeviceName resolves the vdpa bus name for the VF at pciAddr, or "" if none exists.
+func (h *HostManager) GetVdpaDeviceName(pciAddr string) (string, error) {
+	return newVdpaManager(h).GetVdpaDeviceName(pciAddr)
+}
@@ -0,0 +1,137 @@
+// Package vdpa manages vdpa devices layered on top of SR-IOV VFs, so a VfGroup can request
+// "vhost_vdpa"/"virtio_vdpa" as its DeviceType the same way it already requests a DPDK driver.
+package vdpa
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/k8snetworkplumbingwg/govdpa/pkg/kvdpa"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/busdriver"
+)
+
+// driverBinder is the slice of HostManager this package needs, kept as its own interface so tests
+// can inject a fake instead of a real HostManager.
+type driverBinder interface {
+	BindDriverByBusAndDevice(bus, device, driver string) error
+	UnbindDriverByBusAndDevice(bus, device string) error
+}
+
+// netlinkClient is the govdpa surface this package uses, factored out so tests can fake it instead
+// of driving real vdpa genetlink.
+type netlinkClient interface {
+	// AddVdpaDevice creates a vdpa device named name on top of the VF at pciAddr
+	AddVdpaDevice(pciAddr, name string) error
+	// DeleteVdpaDevice removes the vdpa device named name
+	DeleteVdpaDevice(name string) error
+	// VdpaDeviceName resolves the vdpa bus name for the VF at pciAddr, or "" if none exists
+	VdpaDeviceName(pciAddr string) (string, error)
+}
+
+//go:generate ../../../bin/mockgen -destination mock/mock_vdpa.go -source vdpa.go
+type VdpaManager interface {
+	// EnsureVdpaDevice makes sure a vdpa device exists on top of the VF at pciAddr and is bound to
+	// driver ("vhost_vdpa" or "virtio_vdpa"), creating/binding it if missing and re-binding it if
+	// it's already bound to the other vdpa driver. It's a no-op if already in the desired state.
+	EnsureVdpaDevice(pciAddr, driver string) error
+	// RemoveVdpaDevice unbinds and deletes the vdpa device on top of the VF at pciAddr, if any
+	RemoveVdpaDevice(pciAddr string) error
+	// GetVdpaDeviceName resolves the vdpa bus name (e.g. "vdpa0") for the VF at pciAddr, or ""
+	// if no vdpa device exists for it
+	GetVdpaDeviceName(pciAddr string) (string, error)
+}
+
+type vdpaManager struct {
+	binder driverBinder
+	client netlinkClient
+}
+
+// New returns a VdpaManager that drives real vdpa netlink via govdpa and binds/unbinds through
+// binder (normally the HostManager embedding it).
+func New(binder driverBinder) VdpaManager {
+	return newWithClient(binder, &govdpaClient{})
+}
+
+func newWithClient(binder driverBinder, client netlinkClient) VdpaManager {
+	return &vdpaManager{binder: binder, client: client}
+}
+
+func (m *vdpaManager) GetVdpaDeviceName(pciAddr string) (string, error) {
+	name, err := m.client.VdpaDeviceName(pciAddr)
+	if err != nil {
+		return "", fmt.Errorf("GetVdpaDeviceName(): failed to resolve vdpa device for %s: %w", pciAddr, err)
+	}
+	return name, nil
+}
+
+func (m *vdpaManager) EnsureVdpaDevice(pciAddr, driver string) error {
+	log.Log.Info("EnsureVdpaDevice()", "device", pciAddr, "driver", driver)
+
+	name, err := m.GetVdpaDeviceName(pciAddr)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = vdpaDeviceName(pciAddr)
+		if err := m.client.AddVdpaDevice(pciAddr, name); err != nil {
+			return fmt.Errorf("EnsureVdpaDevice(): failed to create vdpa device for %s: %w", pciAddr, err)
+		}
+	}
+
+	if err := m.binder.BindDriverByBusAndDevice(consts.BusVdpa, name, driver); err != nil {
+		return fmt.Errorf("EnsureVdpaDevice(): failed to bind vdpa device %s to %s: %w", name, driver, err)
+	}
+	return nil
+}
+
+func (m *vdpaManager) RemoveVdpaDevice(pciAddr string) error {
+	log.Log.Info("RemoveVdpaDevice()", "device", pciAddr)
+
+	name, err := m.GetVdpaDeviceName(pciAddr)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return nil
+	}
+
+	// A vdpa device can exist with no driver bound (e.g. a stale device left by a crash between
+	// AddVdpaDevice and the bind below), which is fine here: there's simply nothing to unbind
+	// before deleting it.
+	if err := m.binder.UnbindDriverByBusAndDevice(consts.BusVdpa, name); err != nil && !errors.Is(err, busdriver.ErrNoDriverBound) {
+		return fmt.Errorf("RemoveVdpaDevice(): failed to unbind vdpa device %s: %w", name, err)
+	}
+	if err := m.client.DeleteVdpaDevice(name); err != nil {
+		return fmt.Errorf("RemoveVdpaDevice(): failed to delete vdpa device %s: %w", name, err)
+	}
+	return nil
+}
+
+func vdpaDeviceName(pciAddr string) string {
+	return "vdpa:" + pciAddr
+}
+
+// govdpaClient is the real netlinkClient backed by k8snetworkplumbingwg/govdpa.
+type govdpaClient struct{}
+
+func (c *govdpaClient) AddVdpaDevice(pciAddr, name string) error {
+	return kvdpa.CreateVdpaDevice(pciAddr, name)
+}
+
+func (c *govdpaClient) DeleteVdpaDevice(name string) error {
+	return kvdpa.DeleteVdpaDevice(name)
+}
+
+func (c *govdpaClient) VdpaDeviceName(pciAddr string) (string, error) {
+	dev, err := kvdpa.GetVdpaDeviceByPci(pciAddr)
+	if err != nil {
+		if kvdpa.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return dev.Name(), nil
+}
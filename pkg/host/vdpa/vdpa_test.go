@@ -0,0 +1,116 @@
+package vdpa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host/internal/lib/busdriver"
+)
+
+type fakeBinder struct {
+	bound        map[string]string // device -> driver
+	unbound      []string
+	unbindNoDrvr bool // UnbindDriverByBusAndDevice returns busdriver.ErrNoDriverBound
+}
+
+func newFakeBinder() *fakeBinder {
+	return &fakeBinder{bound: map[string]string{}}
+}
+
+func (f *fakeBinder) BindDriverByBusAndDevice(_, device, driver string) error {
+	f.bound[device] = driver
+	return nil
+}
+
+func (f *fakeBinder) UnbindDriverByBusAndDevice(_, device string) error {
+	if f.unbindNoDrvr {
+		return busdriver.ErrNoDriverBound
+	}
+	delete(f.bound, device)
+	f.unbound = append(f.unbound, device)
+	return nil
+}
+
+type fakeClient struct {
+	devices map[string]string // pciAddr -> vdpa name
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{devices: map[string]string{}}
+}
+
+func (f *fakeClient) AddVdpaDevice(pciAddr, name string) error {
+	f.devices[pciAddr] = name
+	return nil
+}
+
+func (f *fakeClient) DeleteVdpaDevice(name string) error {
+	for pciAddr, n := range f.devices {
+		if n == name {
+			delete(f.devices, pciAddr)
+		}
+	}
+	return nil
+}
+
+func (f *fakeClient) VdpaDeviceName(pciAddr string) (string, error) {
+	return f.devices[pciAddr], nil
+}
+
+func TestEnsureVdpaDeviceCreatesAndBinds(t *testing.T) {
+	binder := newFakeBinder()
+	client := newFakeClient()
+	m := newWithClient(binder, client)
+
+	require.NoError(t, m.EnsureVdpaDevice("0000:3b:00.1", "vhost_vdpa"))
+
+	name, err := m.GetVdpaDeviceName("0000:3b:00.1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, name)
+	assert.Equal(t, "vhost_vdpa", binder.bound[name])
+}
+
+func TestEnsureVdpaDeviceIsIdempotentAcrossDriverSwitch(t *testing.T) {
+	binder := newFakeBinder()
+	client := newFakeClient()
+	m := newWithClient(binder, client)
+
+	require.NoError(t, m.EnsureVdpaDevice("0000:3b:00.1", "vhost_vdpa"))
+	name, err := m.GetVdpaDeviceName("0000:3b:00.1")
+	require.NoError(t, err)
+
+	require.NoError(t, m.EnsureVdpaDevice("0000:3b:00.1", "virtio_vdpa"))
+	assert.Equal(t, 1, len(client.devices), "switching driver must reuse the existing vdpa device")
+	assert.Equal(t, "virtio_vdpa", binder.bound[name])
+}
+
+func TestRemoveVdpaDeviceUnbindsAndDeletes(t *testing.T) {
+	binder := newFakeBinder()
+	client := newFakeClient()
+	m := newWithClient(binder, client)
+
+	require.NoError(t, m.EnsureVdpaDevice("0000:3b:00.1", "vhost_vdpa"))
+	name, err := m.GetVdpaDeviceName("0000:3b:00.1")
+	require.NoError(t, err)
+
+	require.NoError(t, m.RemoveVdpaDevice("0000:3b:00.1"))
+	assert.Contains(t, binder.unbound, name)
+	assert.Empty(t, client.devices)
+
+	// removing again is a no-op
+	require.NoError(t, m.RemoveVdpaDevice("0000:3b:00.1"))
+}
+
+func TestRemoveVdpaDeviceToleratesNoDriverBound(t *testing.T) {
+	binder := newFakeBinder()
+	client := newFakeClient()
+	m := newWithClient(binder, client)
+
+	require.NoError(t, m.EnsureVdpaDevice("0000:3b:00.1", "vhost_vdpa"))
+	binder.unbindNoDrvr = true
+
+	require.NoError(t, m.RemoveVdpaDevice("0000:3b:00.1"))
+	assert.Empty(t, client.devices, "vdpa device must still be deleted when there was nothing to unbind")
+}
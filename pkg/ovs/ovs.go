@@ -0,0 +1,345 @@
+package ovs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vars"
+)
+
+const (
+	ovsDBName   = "Open_vSwitch"
+	dialTimeout = 5 * time.Second
+
+	// operatorExternalIDKey tags every bridge this package creates, so RemoveBridge-on-drift logic
+	// in the generic plugin can tell an operator-managed bridge apart from one set up by some other
+	// tool and never touch the latter.
+	operatorExternalIDKey = "sriov-network-operator"
+)
+
+// Interface manages OVS software bridges through the local OVSDB server, so the operator can
+// attach SR-IOV uplinks to switchdev-mode bridges declared in SriovNetworkNodeStateSpec.Bridges.
+//
+//go:generate ../../bin/mockgen -destination mock/mock_ovs.go -source ovs.go
+type Interface interface {
+	// GetOVSBridges returns all OVS bridges currently configured on the host
+	GetOVSBridges() ([]sriovnetworkv1.OVSBridge, error)
+	// EnsureBridge creates the bridge described by spec if it doesn't already match, and is a no-op otherwise
+	EnsureBridge(spec *sriovnetworkv1.OVSBridge) error
+	// RemoveBridge deletes the named bridge if it exists
+	RemoveBridge(name string) error
+	// AttachUplink attaches an SR-IOV uplink interface to an existing bridge with the given config
+	AttachUplink(bridge, ifName string, cfg sriovnetworkv1.OVSUplinkConfig) error
+}
+
+// IsOperatorManaged reports whether bridge carries the external_id this package tags its own
+// bridges with. Bridges set up by anything else must never be touched by RemoveBridge.
+func IsOperatorManaged(bridge sriovnetworkv1.OVSBridge) bool {
+	return bridge.ExternalIDs[operatorExternalIDKey] == "true"
+}
+
+type ovsClient struct {
+	socketPath string
+}
+
+// New returns an Interface that talks to the OVSDB server over the socket configured in
+// vars.OVSDBSocketPath
+func New() Interface {
+	return &ovsClient{socketPath: vars.OVSDBSocketPath}
+}
+
+// transact sends a single "transact" JSON-RPC request with the given operations and returns the
+// raw per-operation results.
+func (c *ovsClient) transact(ops []map[string]interface{}) ([]map[string]interface{}, error) {
+	conn, err := net.DialTimeout("unix", c.socketPath, dialTimeout)
+	if err != nil {
+		log.Log.Error(err, "ovsClient transact(): failed to connect to OVSDB socket", "path", c.socketPath)
+		return nil, err
+	}
+	defer conn.Close()
+
+	params := make([]interface{}, 0, len(ops)+1)
+	params = append(params, ovsDBName)
+	for _, op := range ops {
+		params = append(params, op)
+	}
+
+	req := map[string]interface{}{
+		"method": "transact",
+		"params": params,
+		"id":     0,
+	}
+
+	enc := json.NewEncoder(conn)
+	if err := enc.Encode(req); err != nil {
+		return nil, fmt.Errorf("ovsClient transact(): failed to send request: %w", err)
+	}
+
+	var resp struct {
+		Result []map[string]interface{} `json:"result"`
+		Error  interface{}              `json:"error"`
+	}
+	dec := json.NewDecoder(conn)
+	if err := dec.Decode(&resp); err != nil {
+		return nil, fmt.Errorf("ovsClient transact(): failed to read response: %w", err)
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("ovsClient transact(): OVSDB error: %v", resp.Error)
+	}
+
+	return resp.Result, nil
+}
+
+func (c *ovsClient) GetOVSBridges() ([]sriovnetworkv1.OVSBridge, error) {
+	log.Log.V(2).Info("ovsClient GetOVSBridges()")
+	rows, err := c.transact([]map[string]interface{}{
+		{
+			"op":      "select",
+			"table":   "Bridge",
+			"where":   []interface{}{},
+			"columns": []string{"name", "ports", "datapath_type", "external_ids"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bridges := make([]sriovnetworkv1.OVSBridge, 0, len(rows))
+	for _, row := range rows {
+		rowsField, ok := row["rows"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, r := range rowsField {
+			rowMap, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := rowMap["name"].(string)
+			datapathType, _ := rowMap["datapath_type"].(string)
+			bridges = append(bridges, sriovnetworkv1.OVSBridge{
+				Name:         name,
+				DatapathType: datapathType,
+				ExternalIDs:  parseOVSDBMap(rowMap["external_ids"]),
+			})
+		}
+	}
+
+	return bridges, nil
+}
+
+// parseOVSDBMap decodes an OVSDB "map" column value, encoded as ["map",[[k1,v1],[k2,v2],...]],
+// into a plain Go map. Columns with no entries are represented as the bare string "set" rather
+// than a "map" wrapper, so that case returns an empty map instead of an error.
+func parseOVSDBMap(v interface{}) map[string]string {
+	result := map[string]string{}
+	pair, ok := v.([]interface{})
+	if !ok || len(pair) != 2 || pair[0] != "map" {
+		return result
+	}
+	entries, ok := pair[1].([]interface{})
+	if !ok {
+		return result
+	}
+	for _, e := range entries {
+		kv, ok := e.([]interface{})
+		if !ok || len(kv) != 2 {
+			continue
+		}
+		key, _ := kv[0].(string)
+		value, _ := kv[1].(string)
+		result[key] = value
+	}
+	return result
+}
+
+func (c *ovsClient) EnsureBridge(spec *sriovnetworkv1.OVSBridge) error {
+	log.Log.Info("ovsClient EnsureBridge()", "name", spec.Name)
+	existing, err := c.GetOVSBridges()
+	if err != nil {
+		return err
+	}
+
+	externalIDs := map[string]string{operatorExternalIDKey: "true"}
+	for k, v := range spec.ExternalIDs {
+		externalIDs[k] = v
+	}
+
+	for _, b := range existing {
+		if b.Name != spec.Name {
+			continue
+		}
+		if b.DatapathType == spec.DatapathType && reflect.DeepEqual(b.ExternalIDs, externalIDs) {
+			log.Log.V(2).Info("ovsClient EnsureBridge(): bridge already matches desired state", "name", spec.Name)
+			return nil
+		}
+		log.Log.V(2).Info("ovsClient EnsureBridge(): bridge drifted from desired state, updating", "name", spec.Name)
+		_, err := c.transact([]map[string]interface{}{
+			{
+				"op":    "update",
+				"table": "Bridge",
+				"where": []interface{}{[]interface{}{"name", "==", spec.Name}},
+				"row": map[string]interface{}{
+					"datapath_type": spec.DatapathType,
+					"external_ids":  toOVSDBMap(externalIDs),
+				},
+			},
+		})
+		if err != nil {
+			log.Log.Error(err, "ovsClient EnsureBridge(): failed to update bridge", "name", spec.Name)
+		}
+		return err
+	}
+
+	// A Bridge row that isn't reachable from the Open_vSwitch root gets garbage-collected at
+	// commit, so the insert must also mutate the root's "bridges" set to reference it, the same
+	// way AttachUplink mutates Bridge.ports to reference the new Port.
+	_, err = c.transact([]map[string]interface{}{
+		{
+			"op":        "insert",
+			"table":     "Bridge",
+			"uuid-name": "newbridge",
+			"row": map[string]interface{}{
+				"name":          spec.Name,
+				"datapath_type": spec.DatapathType,
+				"external_ids":  toOVSDBMap(externalIDs),
+			},
+		},
+		{
+			"op":        "mutate",
+			"table":     "Open_vSwitch",
+			"where":     []interface{}{},
+			"mutations": []interface{}{[]interface{}{"bridges", "insert", []interface{}{"set", []interface{}{[]interface{}{"named-uuid", "newbridge"}}}}},
+		},
+	})
+	if err != nil {
+		log.Log.Error(err, "ovsClient EnsureBridge(): failed to create bridge", "name", spec.Name)
+		return err
+	}
+
+	return nil
+}
+
+// toOVSDBMap encodes a plain Go map as an OVSDB "map" column value: ["map",[[k1,v1],...]]
+func toOVSDBMap(m map[string]string) []interface{} {
+	entries := make([]interface{}, 0, len(m))
+	for k, v := range m {
+		entries = append(entries, []interface{}{k, v})
+	}
+	return []interface{}{"map", entries}
+}
+
+// findBridgeUUID returns the OVSDB row UUID of the Bridge named name, if it exists. The UUID is
+// needed to remove it from the Open_vSwitch root's "bridges" set, which only accepts concrete
+// UUIDs (or named-uuids from inserts earlier in the same transaction), not a name-based where
+// clause.
+func (c *ovsClient) findBridgeUUID(name string) (string, bool, error) {
+	rows, err := c.transact([]map[string]interface{}{
+		{
+			"op":      "select",
+			"table":   "Bridge",
+			"where":   []interface{}{[]interface{}{"name", "==", name}},
+			"columns": []string{"_uuid"},
+		},
+	})
+	if err != nil {
+		return "", false, err
+	}
+	for _, row := range rows {
+		rowsField, ok := row["rows"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, r := range rowsField {
+			rowMap, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if uuid, ok := parseOVSDBUUID(rowMap["_uuid"]); ok {
+				return uuid, true, nil
+			}
+		}
+	}
+	return "", false, nil
+}
+
+// parseOVSDBUUID decodes an OVSDB "uuid" column value, encoded as ["uuid","<uuid>"].
+func parseOVSDBUUID(v interface{}) (string, bool) {
+	pair, ok := v.([]interface{})
+	if !ok || len(pair) != 2 || pair[0] != "uuid" {
+		return "", false
+	}
+	uuid, ok := pair[1].(string)
+	return uuid, ok
+}
+
+func (c *ovsClient) RemoveBridge(name string) error {
+	log.Log.Info("ovsClient RemoveBridge()", "name", name)
+	uuid, found, err := c.findBridgeUUID(name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		log.Log.V(2).Info("ovsClient RemoveBridge(): bridge does not exist, nothing to do", "name", name)
+		return nil
+	}
+
+	// The still-referenced Bridge row must be dropped from the Open_vSwitch root's "bridges" set
+	// in the same transaction as the row delete, or the delete fails referential integrity.
+	_, err = c.transact([]map[string]interface{}{
+		{
+			"op":        "mutate",
+			"table":     "Open_vSwitch",
+			"where":     []interface{}{},
+			"mutations": []interface{}{[]interface{}{"bridges", "delete", []interface{}{"set", []interface{}{[]interface{}{"uuid", uuid}}}}},
+		},
+		{
+			"op":    "delete",
+			"table": "Bridge",
+			"where": []interface{}{[]interface{}{"name", "==", name}},
+		},
+	})
+	if err != nil {
+		log.Log.Error(err, "ovsClient RemoveBridge(): failed to remove bridge", "name", name)
+		return err
+	}
+	return nil
+}
+
+func (c *ovsClient) AttachUplink(bridge, ifName string, cfg sriovnetworkv1.OVSUplinkConfig) error {
+	log.Log.Info("ovsClient AttachUplink()", "bridge", bridge, "interface", ifName)
+	_, err := c.transact([]map[string]interface{}{
+		{
+			"op":        "insert",
+			"table":     "Interface",
+			"row":       map[string]interface{}{"name": ifName},
+			"uuid-name": "newiface",
+		},
+		{
+			"op":    "insert",
+			"table": "Port",
+			"row": map[string]interface{}{
+				"name":       ifName,
+				"interfaces": []interface{}{"named-uuid", "newiface"},
+			},
+			"uuid-name": "newport",
+		},
+		{
+			"op":        "mutate",
+			"table":     "Bridge",
+			"where":     []interface{}{[]interface{}{"name", "==", bridge}},
+			"mutations": []interface{}{[]interface{}{"ports", "insert", []interface{}{"set", []interface{}{[]interface{}{"named-uuid", "newport"}}}}},
+		},
+	})
+	if err != nil {
+		log.Log.Error(err, "ovsClient AttachUplink(): failed to attach uplink", "bridge", bridge, "interface", ifName)
+		return err
+	}
+	return nil
+}
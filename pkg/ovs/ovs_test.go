@@ -0,0 +1,141 @@
+package ovs
+
+import (
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+// fakeOVSDBExchange accepts len(resultsPerCall) sequential connections on l - ovsClient opens one
+// connection per transact() call - decoding the "transact" params sent on each and replying with
+// the corresponding canned result. It returns the decoded operations for every call, so tests can
+// assert not just that a call happened but which table/mutation it touched.
+func fakeOVSDBExchange(t *testing.T, l net.Listener, resultsPerCall [][]map[string]interface{}) [][]map[string]interface{} {
+	captured := make([][]map[string]interface{}, len(resultsPerCall))
+	for i, results := range resultsPerCall {
+		conn, err := l.Accept()
+		require.NoError(t, err)
+
+		var req struct {
+			Params []json.RawMessage `json:"params"`
+			ID     int               `json:"id"`
+		}
+		require.NoError(t, json.NewDecoder(conn).Decode(&req))
+
+		ops := make([]map[string]interface{}, 0, len(req.Params)-1)
+		for _, p := range req.Params[1:] { // params[0] is the DB name, the rest are operations
+			var op map[string]interface{}
+			require.NoError(t, json.Unmarshal(p, &op))
+			ops = append(ops, op)
+		}
+		captured[i] = ops
+
+		require.NoError(t, json.NewEncoder(conn).Encode(map[string]interface{}{
+			"result": results,
+			"error":  nil,
+			"id":     req.ID,
+		}))
+		conn.Close()
+	}
+	return captured
+}
+
+func newFakeOVSDBSocket(t *testing.T) (net.Listener, string) {
+	sockPath := filepath.Join(t.TempDir(), "ovsdb.sock")
+	l, err := net.Listen("unix", sockPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	return l, sockPath
+}
+
+func TestEnsureBridgeCreateMutatesRootBridges(t *testing.T) {
+	l, sockPath := newFakeOVSDBSocket(t)
+	c := &ovsClient{socketPath: sockPath}
+
+	capturedCh := make(chan [][]map[string]interface{}, 1)
+	go func() {
+		capturedCh <- fakeOVSDBExchange(t, l, [][]map[string]interface{}{
+			{{"rows": []interface{}{}}}, // GetOVSBridges(): no existing bridges
+			{{}, {}},                    // insert + mutate
+		})
+	}()
+
+	require.NoError(t, c.EnsureBridge(&sriovnetworkv1.OVSBridge{Name: "br-sriov", DatapathType: "netdev"}))
+
+	captured := <-capturedCh
+	require.Len(t, captured, 2)
+	createOps := captured[1]
+	require.Len(t, createOps, 2, "bridge insert must be paired with a root mutate or the row is unreachable")
+
+	assert.Equal(t, "insert", createOps[0]["op"])
+	assert.Equal(t, "Bridge", createOps[0]["table"])
+	uuidName := createOps[0]["uuid-name"]
+	require.NotEmpty(t, uuidName, "insert needs a uuid-name so the mutate below can reference it")
+
+	assert.Equal(t, "mutate", createOps[1]["op"])
+	assert.Equal(t, "Open_vSwitch", createOps[1]["table"])
+	mutations, ok := createOps[1]["mutations"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, mutations, 1)
+	mutation, ok := mutations[0].([]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "bridges", mutation[0])
+	assert.Equal(t, "insert", mutation[1])
+}
+
+func TestRemoveBridgeMutatesRootBridges(t *testing.T) {
+	l, sockPath := newFakeOVSDBSocket(t)
+	c := &ovsClient{socketPath: sockPath}
+
+	const bridgeUUID = "11111111-2222-3333-4444-555555555555"
+	capturedCh := make(chan [][]map[string]interface{}, 1)
+	go func() {
+		capturedCh <- fakeOVSDBExchange(t, l, [][]map[string]interface{}{
+			{{"rows": []interface{}{
+				map[string]interface{}{"_uuid": []interface{}{"uuid", bridgeUUID}},
+			}}}, // findBridgeUUID()
+			{{}, {}}, // mutate + delete
+		})
+	}()
+
+	require.NoError(t, c.RemoveBridge("br-sriov"))
+
+	captured := <-capturedCh
+	require.Len(t, captured, 2)
+	removeOps := captured[1]
+	require.Len(t, removeOps, 2, "bridge delete must be paired with a root mutate or it fails referential integrity")
+
+	assert.Equal(t, "mutate", removeOps[0]["op"])
+	assert.Equal(t, "Open_vSwitch", removeOps[0]["table"])
+	mutations, ok := removeOps[0]["mutations"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, mutations, 1)
+	mutation, ok := mutations[0].([]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "bridges", mutation[0])
+	assert.Equal(t, "delete", mutation[1])
+
+	assert.Equal(t, "delete", removeOps[1]["op"])
+	assert.Equal(t, "Bridge", removeOps[1]["table"])
+}
+
+func TestRemoveBridgeNoSuchBridgeIsNoop(t *testing.T) {
+	l, sockPath := newFakeOVSDBSocket(t)
+	c := &ovsClient{socketPath: sockPath}
+
+	capturedCh := make(chan [][]map[string]interface{}, 1)
+	go func() {
+		capturedCh <- fakeOVSDBExchange(t, l, [][]map[string]interface{}{
+			{{"rows": []interface{}{}}}, // findBridgeUUID(): no such bridge
+		})
+	}()
+
+	require.NoError(t, c.RemoveBridge("br-does-not-exist"))
+	<-capturedCh // only the lookup call should have happened; no second connection is attempted
+}
@@ -1,7 +1,12 @@
 package openshift
 
 import (
+	"context"
+
 	mcclientset "github.com/openshift/machine-config-operator/pkg/generated/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
@@ -15,8 +20,18 @@ type OpenshiftFlavor string
 const (
 	// Hypershift flavor of openshift: https://github.com/openshift/hypershift
 	OpenshiftFlavorHypershift OpenshiftFlavor = "hypershift"
+	// OpenshiftFlavorMicroShift is MicroShift, which ships without the Machine Config Operator
+	OpenshiftFlavorMicroShift OpenshiftFlavor = "microshift"
+	// OpenshiftFlavorSingleNode is a Single-Node OpenShift (SNO) cluster: a normal OpenShift install
+	// whose Infrastructure.Status.ControlPlaneTopology reports a single replica
+	OpenshiftFlavorSingleNode OpenshiftFlavor = "single-node"
 	// OpenshiftFlavorDefault covers all remaining flavors of openshift not explicitly called out above
 	OpenshiftFlavorDefault OpenshiftFlavor = "default"
+
+	// microshiftVersionConfigMapName is the ConfigMap MicroShift publishes to advertise its version;
+	// its presence alone is how we tell MicroShift apart from OpenShift
+	microshiftVersionConfigMapName      = "microshift-version"
+	microshiftVersionConfigMapNamespace = "kube-public"
 )
 
 //go:generate ../../../bin/mockgen -destination mock/mock_openshift.go -source openshift.go
@@ -25,6 +40,10 @@ type OpenshiftContextInterface interface {
 	GetMcClient() mcclientset.Interface
 	IsOpenshiftCluster() bool
 	IsHypershift() bool
+	// IsSingleNode returns true on Single-Node OpenShift, where there are no peer nodes to drain to
+	IsSingleNode() bool
+	// IsMicroShift returns true on MicroShift, which has no Machine Config Operator to reboot through
+	IsMicroShift() bool
 }
 
 // OpenshiftContext contains metadata and structs utilized to interact with Openshift clusters
@@ -57,18 +76,53 @@ func NewOpenshiftContext() (OpenshiftContextInterface, error) {
 		return nil, err
 	}
 
-	isHypershift, err := utils.IsExternalControlPlaneCluster(infraClient)
+	isMicroShift, err := isMicroShiftCluster(infraClient)
 	if err != nil {
 		return nil, err
 	}
 
-	if isHypershift {
-		openshiftFlavor = OpenshiftFlavorHypershift
+	switch {
+	case isMicroShift:
+		openshiftFlavor = OpenshiftFlavorMicroShift
+	default:
+		isHypershift, err := utils.IsExternalControlPlaneCluster(infraClient)
+		if err != nil {
+			return nil, err
+		}
+		if isHypershift {
+			openshiftFlavor = OpenshiftFlavorHypershift
+			break
+		}
+
+		isSingleNode, err := utils.IsSingleNodeCluster(infraClient)
+		if err != nil {
+			return nil, err
+		}
+		if isSingleNode {
+			openshiftFlavor = OpenshiftFlavorSingleNode
+		}
 	}
 
 	return &OpenshiftContext{mcclient, true, openshiftFlavor}, nil
 }
 
+// isMicroShiftCluster detects MicroShift by the presence of its version ConfigMap, which ships on
+// MicroShift but not on full OpenShift (which instead has the Machine Config Operator CRDs).
+func isMicroShiftCluster(c client.Client) (bool, error) {
+	cm := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      microshiftVersionConfigMapName,
+		Namespace: microshiftVersionConfigMapNamespace,
+	}, cm)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (c *OpenshiftContext) GetFlavor() OpenshiftFlavor {
 	return c.OpenshiftFlavor
 }
@@ -84,3 +138,11 @@ func (c OpenshiftContext) IsOpenshiftCluster() bool {
 func (c OpenshiftContext) IsHypershift() bool {
 	return c.OpenshiftFlavor == OpenshiftFlavorHypershift
 }
+
+func (c OpenshiftContext) IsSingleNode() bool {
+	return c.OpenshiftFlavor == OpenshiftFlavorSingleNode
+}
+
+func (c OpenshiftContext) IsMicroShift() bool {
+	return c.OpenshiftFlavor == OpenshiftFlavorMicroShift
+}
@@ -0,0 +1,214 @@
+// Package poolpause lets the drain controller coordinate with whatever update-pool mechanism owns
+// a node before cordoning/draining it, and resume that pool once the drain completes. This
+// generalizes the OpenShift Bug 1960103 MachineConfigPool-pause behavior to vanilla Kubernetes
+// clusters managed by Cluster API or another pool controller.
+package poolpause
+
+import (
+	"context"
+	"fmt"
+
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+// Kind selects which PoolPauser implementation NewForPool returns.
+type Kind string
+
+const (
+	// KindMachineConfigPool pauses the OpenShift MachineConfigPool that selects the node.
+	KindMachineConfigPool Kind = "MachineConfigPool"
+	// KindCAPIMachineDeployment pauses the Cluster API MachineDeployment that owns the node.
+	KindCAPIMachineDeployment Kind = "CAPIMachineDeployment"
+	// KindNone never pauses anything; IsPaused always reports true so callers don't block on it.
+	KindNone Kind = "None"
+
+	// capiPausedAnnotation is the Cluster API convention for pausing reconciliation of an object.
+	// See https://cluster-api.sigs.k8s.io/developer/providers/contracts#pause
+	capiPausedAnnotation = "cluster.x-k8s.io/paused"
+
+	// machineDeploymentNameLabel is set by Cluster API on every Node belonging to a MachineDeployment.
+	machineDeploymentNameLabel = "cluster.x-k8s.io/deployment-name"
+)
+
+var machineDeploymentGVK = schema.GroupVersionKind{
+	Group:   "cluster.x-k8s.io",
+	Version: "v1beta1",
+	Kind:    "MachineDeployment",
+}
+
+var machineDeploymentListGVK = schema.GroupVersionKind{
+	Group:   "cluster.x-k8s.io",
+	Version: "v1beta1",
+	Kind:    "MachineDeploymentList",
+}
+
+// PoolPauser pauses and resumes the update pool that owns a node, so the drain controller can
+// treat "pool not yet paused" as a requeue condition instead of proceeding to cordon/drain.
+type PoolPauser interface {
+	// Pause requests that the pool owning node stop rolling out further changes.
+	Pause(ctx context.Context, node *corev1.Node) error
+	// Unpause allows the pool owning node to resume rolling out changes.
+	Unpause(ctx context.Context, node *corev1.Node) error
+	// IsPaused reports whether the pool owning node is currently paused.
+	IsPaused(ctx context.Context, node *corev1.Node) (bool, error)
+}
+
+// NewForPool returns the PoolPauser implementation selected by poolConfig's pauser kind, defaulting
+// to a no-op pauser for pool configs that don't opt into one.
+func NewForPool(c client.Client, poolConfig *sriovnetworkv1.SriovNetworkPoolConfig) PoolPauser {
+	switch Kind(poolConfig.Spec.PoolPauserKind) {
+	case KindMachineConfigPool:
+		return &mcpPauser{client: c}
+	case KindCAPIMachineDeployment:
+		return &capiPauser{client: c}
+	default:
+		return noopPauser{}
+	}
+}
+
+// mcpPauser pauses the OpenShift MachineConfigPool whose NodeSelector matches the node, matching
+// the behavior OCP Bug 1960103 introduced for the SR-IOV operator's own drain path.
+type mcpPauser struct {
+	client client.Client
+}
+
+func (p *mcpPauser) Pause(ctx context.Context, node *corev1.Node) error {
+	pool, err := p.poolForNode(ctx, node)
+	if err != nil {
+		return err
+	}
+	return p.setPaused(ctx, pool, true)
+}
+
+func (p *mcpPauser) Unpause(ctx context.Context, node *corev1.Node) error {
+	pool, err := p.poolForNode(ctx, node)
+	if err != nil {
+		return err
+	}
+	return p.setPaused(ctx, pool, false)
+}
+
+func (p *mcpPauser) IsPaused(ctx context.Context, node *corev1.Node) (bool, error) {
+	pool, err := p.poolForNode(ctx, node)
+	if err != nil {
+		return false, err
+	}
+	return pool.Spec.Paused, nil
+}
+
+func (p *mcpPauser) setPaused(ctx context.Context, pool *mcfgv1.MachineConfigPool, paused bool) error {
+	if pool.Spec.Paused == paused {
+		return nil
+	}
+	patch := client.MergeFrom(pool.DeepCopy())
+	pool.Spec.Paused = paused
+	return p.client.Patch(ctx, pool, patch)
+}
+
+func (p *mcpPauser) poolForNode(ctx context.Context, node *corev1.Node) (*mcfgv1.MachineConfigPool, error) {
+	pools := &mcfgv1.MachineConfigPoolList{}
+	if err := p.client.List(ctx, pools); err != nil {
+		return nil, err
+	}
+
+	for i := range pools.Items {
+		pool := &pools.Items[i]
+		selector, err := metav1.LabelSelectorAsSelector(pool.Spec.NodeSelector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(node.Labels)) {
+			return pool, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no MachineConfigPool selects node %q", node.Name)
+}
+
+// capiPauser pauses the Cluster API MachineDeployment that owns the node by setting the
+// cluster.x-k8s.io/paused annotation. It talks to MachineDeployment as unstructured data so this
+// operator doesn't need to take on a Cluster API client dependency just to flip one annotation.
+type capiPauser struct {
+	client client.Client
+}
+
+func (p *capiPauser) Pause(ctx context.Context, node *corev1.Node) error {
+	return p.setPaused(ctx, node, true)
+}
+
+func (p *capiPauser) Unpause(ctx context.Context, node *corev1.Node) error {
+	return p.setPaused(ctx, node, false)
+}
+
+func (p *capiPauser) IsPaused(ctx context.Context, node *corev1.Node) (bool, error) {
+	md, err := p.machineDeployment(ctx, node)
+	if err != nil {
+		return false, err
+	}
+	_, paused := md.GetAnnotations()[capiPausedAnnotation]
+	return paused, nil
+}
+
+func (p *capiPauser) setPaused(ctx context.Context, node *corev1.Node, paused bool) error {
+	md, err := p.machineDeployment(ctx, node)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(md.DeepCopy())
+	annotations := md.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if paused {
+		annotations[capiPausedAnnotation] = "true"
+	} else {
+		delete(annotations, capiPausedAnnotation)
+	}
+	md.SetAnnotations(annotations)
+
+	return p.client.Patch(ctx, md, patch)
+}
+
+// machineDeployment resolves the MachineDeployment named by node's machineDeploymentNameLabel.
+// Nodes are cluster-scoped and carry no namespace of their own, so the MachineDeployment's
+// namespace can't come from node.Namespace; list across all namespaces and match on name instead.
+func (p *capiPauser) machineDeployment(ctx context.Context, node *corev1.Node) (*unstructured.Unstructured, error) {
+	mdName, ok := node.Labels[machineDeploymentNameLabel]
+	if !ok {
+		return nil, fmt.Errorf("node %q has no %s label, cannot locate its MachineDeployment", node.Name, machineDeploymentNameLabel)
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(machineDeploymentListGVK)
+	if err := p.client.List(ctx, list); err != nil {
+		return nil, err
+	}
+
+	for i := range list.Items {
+		if list.Items[i].GetName() == mdName {
+			return &list.Items[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("no MachineDeployment named %q found for node %q", mdName, node.Name)
+}
+
+// noopPauser never pauses anything. It backs clusters (Kubeadm, Karpenter, bare-metal) that have
+// no pool mechanism to coordinate with, so the drain controller can treat them as always-paused
+// and proceed straight to cordon/drain.
+type noopPauser struct{}
+
+func (noopPauser) Pause(context.Context, *corev1.Node) error   { return nil }
+func (noopPauser) Unpause(context.Context, *corev1.Node) error { return nil }
+func (noopPauser) IsPaused(context.Context, *corev1.Node) (bool, error) {
+	return true, nil
+}
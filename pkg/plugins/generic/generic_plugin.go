@@ -3,8 +3,8 @@ package generic
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"os/exec"
-	"reflect"
 	"strconv"
 	"strings"
 	"syscall"
@@ -13,7 +13,9 @@ import (
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	constants "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/consts"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/featuregates"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/ovs"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 )
@@ -51,18 +53,48 @@ type GenericPlugin struct {
 	PluginName        string
 	SpecVersion       string
 	DesireState       *sriovnetworkv1.SriovNetworkNodeState
-	LastState         *sriovnetworkv1.SriovNetworkNodeState
 	DriverStateMap    DriverStateMapType
 	DesiredKernelArgs map[string]bool
 	RunningOnHost     bool
 	HostManager       host.HostManagerInterface
 	StoreManager      utils.StoreManagerInterface
+	OVSManager        ovs.Interface
+	// SkipBridgeConfiguration skips OVS bridge reconciliation, so the pre-reboot phase of Apply()
+	// can finish without waiting on OVS bridges that only make sense once the network is up.
+	SkipBridgeConfiguration bool
+	// SkipVFConfiguration skips vdpa driver loading and the switchdev device config file, state that
+	// only matters once VF representors can exist. It does not narrow utils.SyncNodeState, which
+	// still configures the VFs too.
+	SkipVFConfiguration bool
 }
 
 const scriptsPath = "bindata/scripts/enable-kargs.sh"
 
+// GenericPluginOption configures optional behavior of the generic plugin at construction time.
+// Passing WithSkipVFConfiguration() and WithSkipBridgeConfiguration() gives a pre-network run
+// (sriov-config.service, before NetworkManager starts); passing neither, as a post-network run
+// (sriov-config-post-network.service) or the long-running daemon would, does everything. No
+// caller in this repository passes either option.
+type GenericPluginOption func(*GenericPlugin)
+
+// WithSkipBridgeConfiguration makes the plugin skip OVS bridge reconciliation on Apply()
+func WithSkipBridgeConfiguration() GenericPluginOption {
+	return func(p *GenericPlugin) {
+		p.SkipBridgeConfiguration = true
+	}
+}
+
+// WithSkipVFConfiguration makes the plugin skip loading the vdpa drivers on Apply() and writing
+// the switchdev device config file in needRebootNode(). utils.SyncNodeState still runs in full on
+// Apply(), VF configuration included.
+func WithSkipVFConfiguration() GenericPluginOption {
+	return func(p *GenericPlugin) {
+		p.SkipVFConfiguration = true
+	}
+}
+
 // Initialize our plugin and set up initial values
-func NewGenericPlugin(runningOnHost bool, hostManager host.HostManagerInterface, storeManager utils.StoreManagerInterface) (plugin.VendorPlugin, error) {
+func NewGenericPlugin(runningOnHost bool, hostManager host.HostManagerInterface, storeManager utils.StoreManagerInterface, opts ...GenericPluginOption) (plugin.VendorPlugin, error) {
 	driverStateMap := make(map[uint]*DriverState)
 	driverStateMap[Vfio] = &DriverState{
 		DriverName:     vfioPciDriver,
@@ -85,7 +117,7 @@ func NewGenericPlugin(runningOnHost bool, hostManager host.HostManagerInterface,
 		NeedDriverFunc: needDriverCheckVdpaType,
 		DriverLoaded:   false,
 	}
-	return &GenericPlugin{
+	p := &GenericPlugin{
 		PluginName:        PluginName,
 		SpecVersion:       "1.0",
 		DriverStateMap:    driverStateMap,
@@ -93,7 +125,14 @@ func NewGenericPlugin(runningOnHost bool, hostManager host.HostManagerInterface,
 		RunningOnHost:     runningOnHost,
 		HostManager:       hostManager,
 		StoreManager:      storeManager,
-	}, nil
+		OVSManager:        ovs.New(),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p, nil
 }
 
 // Name returns the name of the plugin
@@ -111,6 +150,8 @@ func (p *GenericPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeSt
 	log.Log.Info("generic-plugin OnNodeStateChange()")
 	p.DesireState = new
 
+	p.checkPrerequisites(new)
+
 	needDrain = p.needDrainNode(new.Spec.Interfaces, new.Status.Interfaces)
 	needReboot, err = p.needRebootNode(new)
 	if err != nil {
@@ -124,7 +165,14 @@ func (p *GenericPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeSt
 }
 
 func (p *GenericPlugin) syncDriverState() error {
-	for _, driverState := range p.DriverStateMap {
+	for driverID, driverState := range p.DriverStateMap {
+		// vdpa drivers back VFs that are only wired up once the network stack (and OVS, for
+		// switchdev) is up, so loading them is VF-level configuration; vfio_pci is loaded
+		// regardless of phase because a VF is bound to it as soon as it's created, before any
+		// network stack exists.
+		if p.SkipVFConfiguration && driverID != Vfio {
+			continue
+		}
 		if !driverState.DriverLoaded && driverState.NeedDriverFunc(p.DesireState, driverState) {
 			log.Log.V(2).Info("loading driver", "name", driverState.DriverName)
 			if err := p.HostManager.LoadKernelModule(driverState.DriverName); err != nil {
@@ -141,14 +189,6 @@ func (p *GenericPlugin) syncDriverState() error {
 func (p *GenericPlugin) Apply() error {
 	log.Log.Info("generic-plugin Apply()", "desiredState", p.DesireState.Spec)
 
-	if p.LastState != nil {
-		log.Log.Info("generic-plugin Apply()", "lastState", p.LastState.Spec)
-		if reflect.DeepEqual(p.LastState.Spec.Interfaces, p.DesireState.Spec.Interfaces) {
-			log.Log.Info("generic-plugin Apply(): desired and latest state are the same, nothing to apply")
-			return nil
-		}
-	}
-
 	if err := p.syncDriverState(); err != nil {
 		return err
 	}
@@ -170,6 +210,10 @@ func (p *GenericPlugin) Apply() error {
 		defer exit()
 	}
 
+	// SyncNodeState configures both PF-level state (numVfs, eswitch mode) and VF-level state in a
+	// single pass; this snapshot doesn't expose a PF-only entry point, so it always runs here. The
+	// pre-network phase caller (p.SkipVFConfiguration) relies on its desired state not requesting
+	// anything that needs VFs or the network stack to already exist.
 	if err := utils.SyncNodeState(p.DesireState, pfsToSkip); err != nil {
 		// Catch the "cannot allocate memory" error and try to use PCI realloc
 		if errors.Is(err, syscall.ENOMEM) {
@@ -177,8 +221,86 @@ func (p *GenericPlugin) Apply() error {
 		}
 		return err
 	}
-	p.LastState = &sriovnetworkv1.SriovNetworkNodeState{}
-	*p.LastState = *p.DesireState
+
+	// Switching RDMA subsystem network-namespace mode must happen before any non-init network
+	// namespace exists on the host, so it can only be done safely from the systemd phasePre step
+	// before the daemon itself starts. This
+	// long-running daemon process must never attempt the change directly; it can only confirm
+	// phasePre already applied it.
+	if desiredMode := p.DesireState.Spec.System.RdmaMode; desiredMode != "" {
+		currentMode, err := p.HostManager.GetRDMASubsystemMode()
+		if err != nil {
+			log.Log.Error(err, "generic-plugin Apply(): fail to get RDMA subsystem mode")
+			return err
+		}
+		if !strings.EqualFold(currentMode, desiredMode) {
+			err := fmt.Errorf("RDMA subsystem mode is %q but %q is desired; this can only be changed by the "+
+				"systemd phasePre step followed by a reboot, not by the running daemon", currentMode, desiredMode)
+			log.Log.Error(err, "generic-plugin Apply(): desired RDMA subsystem mode not applied")
+			return err
+		}
+	}
+
+	if err := p.syncBridges(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// syncBridges reconciles the OVS bridges declared in DesireState.Spec.Bridges.OVS against the
+// bridges currently configured on the host, and attaches the uplink of every switchdev-mode PF
+// that a bridge lists. Bridges no longer desired are only removed when ovs.IsOperatorManaged
+// reports that this package created them, so bridges set up by other tooling are left alone.
+func (p *GenericPlugin) syncBridges() error {
+	if p.SkipBridgeConfiguration || !featuregates.Enabled(featuregates.ManageSoftwareBridges) {
+		return nil
+	}
+
+	desired := p.DesireState.Spec.Bridges.OVS
+	existing, err := p.OVSManager.GetOVSBridges()
+	if err != nil {
+		log.Log.Error(err, "generic-plugin syncBridges(): failed to list existing OVS bridges")
+		return err
+	}
+
+	desiredNames := make(map[string]bool, len(desired))
+	for i := range desired {
+		bridge := desired[i]
+		desiredNames[bridge.Name] = true
+		if err := p.OVSManager.EnsureBridge(&bridge); err != nil {
+			log.Log.Error(err, "generic-plugin syncBridges(): failed to reconcile bridge", "name", bridge.Name)
+			return err
+		}
+
+		for _, uplink := range bridge.Uplinks {
+			iface, err := sriovnetworkv1.FindInterface(p.DesireState.Spec.Interfaces, uplink.PFName)
+			if err != nil {
+				log.Log.Error(err, "generic-plugin syncBridges(): fail find uplink interface", "name", uplink.PFName)
+				continue
+			}
+			if iface.EswitchMode != sriovnetworkv1.ESwithModeSwitchDev {
+				continue
+			}
+			if err := p.OVSManager.AttachUplink(bridge.Name, iface.Name, uplink.Uplink); err != nil {
+				log.Log.Error(err, "generic-plugin syncBridges(): failed to attach uplink",
+					"bridge", bridge.Name, "pf", uplink.PFName)
+				return err
+			}
+		}
+	}
+
+	for _, bridge := range existing {
+		if desiredNames[bridge.Name] || !ovs.IsOperatorManaged(bridge) {
+			continue
+		}
+		log.Log.Info("generic-plugin syncBridges(): removing bridge no longer in desired state", "name", bridge.Name)
+		if err := p.OVSManager.RemoveBridge(bridge.Name); err != nil {
+			log.Log.Error(err, "generic-plugin syncBridges(): failed to remove bridge", "name", bridge.Name)
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -256,6 +378,11 @@ func (p *GenericPlugin) syncDesiredKernelArgs() (bool, error) {
 	if len(p.DesiredKernelArgs) == 0 {
 		return false, nil
 	}
+	// Persist the desired set so HostManager.VerifyKernelArgs can still catch a kernel arg that
+	// silently failed to stick even after this process restarts.
+	if err := p.HostManager.SetDesiredKernelArgs(p.DesiredKernelArgs); err != nil {
+		log.Log.Error(err, "generic-plugin syncDesiredKernelArgs(): failed to persist desired kernel args")
+	}
 	kargs, err := utils.GetCurrentKernelArgs(false)
 	if err != nil {
 		return false, err
@@ -294,6 +421,11 @@ func (p *GenericPlugin) needDrainNode(desired sriovnetworkv1.Interfaces, current
 		for _, iface := range desired {
 			if iface.PciAddress == ifaceStatus.PciAddress {
 				configured = true
+				if iface.ExternallyManaged {
+					log.Log.V(2).Info("generic-plugin needDrainNode(): no need drain, PF is externally managed",
+						"address", iface.PciAddress)
+					break
+				}
 				if ifaceStatus.NumVfs == 0 {
 					log.Log.V(2).Info("generic-plugin needDrainNode(): no need drain, for PCI address, current NumVfs is 0",
 						"address", iface.PciAddress)
@@ -305,6 +437,10 @@ func (p *GenericPlugin) needDrainNode(desired sriovnetworkv1.Interfaces, current
 					needDrain = true
 					return
 				}
+				if p.needDriftUpdate(iface, ifaceStatus) {
+					needDrain = true
+					return
+				}
 				log.Log.V(2).Info("generic-plugin needDrainNode(): no need drain,for PCI address",
 					"address", iface.PciAddress, "expected-vfs", iface.NumVfs, "current-vfs", ifaceStatus.NumVfs)
 			}
@@ -341,14 +477,74 @@ func (p *GenericPlugin) needDrainNode(desired sriovnetworkv1.Interfaces, current
 	return
 }
 
+// needDriftUpdate detects two kinds of out-of-band drift that a plain NumVfs/MTU comparison
+// misses: a PF whose link went down (operstate "down") outside of the operator, and an InfiniBand
+// PF/VF whose node/port GUID no longer matches the GUID requested in the policy.
+func (p *GenericPlugin) needDriftUpdate(iface sriovnetworkv1.Interface, ifaceStatus sriovnetworkv1.InterfaceExt) bool {
+	if ifaceStatus.Name != "" {
+		linkUp, err := p.HostManager.IsLinkUp(ifaceStatus.Name)
+		if err != nil {
+			log.Log.Error(err, "generic-plugin needDriftUpdate(): failed to check link state", "address", iface.PciAddress)
+		} else if !linkUp {
+			log.Log.V(2).Info("generic-plugin needDriftUpdate(): need drain, PF link is down out-of-band",
+				"address", iface.PciAddress)
+			return true
+		}
+	}
+
+	if !strings.EqualFold(ifaceStatus.LinkType, constants.LinkTypeIB) {
+		return false
+	}
+
+	for i := range iface.VfGroups {
+		if iface.VfGroups[i].GUID == "" {
+			continue
+		}
+		currentGUID, err := p.HostManager.GetNetDevNodeGUID(iface.PciAddress)
+		if err != nil {
+			log.Log.Error(err, "generic-plugin needDriftUpdate(): failed to check node GUID", "address", iface.PciAddress)
+			continue
+		}
+		if !strings.EqualFold(currentGUID, iface.VfGroups[i].GUID) {
+			log.Log.V(2).Info("generic-plugin needDriftUpdate(): need drain, GUID drifted from policy",
+				"address", iface.PciAddress, "current", currentGUID, "desired", iface.VfGroups[i].GUID)
+			return true
+		}
+	}
+
+	return false
+}
+
 func (p *GenericPlugin) addVfioDesiredKernelArg(state *sriovnetworkv1.SriovNetworkNodeState) {
 	driverState := p.DriverStateMap[Vfio]
 	if !driverState.DriverLoaded && driverState.NeedDriverFunc(state, driverState) {
-		p.addToDesiredKernelArgs(utils.KernelArgIntelIommu)
+		p.addToDesiredKernelArgs(p.iommuKernelArg())
 		p.addToDesiredKernelArgs(utils.KernelArgIommuPt)
 	}
 }
 
+// iommuKernelArg picks the IOMMU kernel argument that will actually take effect on this host's
+// CPU: intel_iommu=on and amd_iommu=on are no-ops on the other vendor's hardware, and neither
+// applies on ARM, which enables its SMMU with a different argument entirely. It falls back to
+// Intel's if the vendor can't be determined, matching this function's previous unconditional
+// behavior.
+func (p *GenericPlugin) iommuKernelArg() string {
+	vendor, err := p.HostManager.GetCPUVendor()
+	if err != nil {
+		log.Log.Error(err, "generic-plugin iommuKernelArg(): failed to detect CPU vendor, defaulting to Intel")
+		return utils.KernelArgIntelIommu
+	}
+
+	switch vendor {
+	case host.CPUVendorAMD:
+		return utils.KernelArgAmdIommu
+	case host.CPUVendorARM:
+		return utils.KernelArgArmSmmu
+	default:
+		return utils.KernelArgIntelIommu
+	}
+}
+
 func (p *GenericPlugin) needRebootNode(state *sriovnetworkv1.SriovNetworkNodeState) (needReboot bool, err error) {
 	needReboot = false
 	p.addVfioDesiredKernelArg(state)
@@ -363,19 +559,55 @@ func (p *GenericPlugin) needRebootNode(state *sriovnetworkv1.SriovNetworkNodeSta
 		needReboot = true
 	}
 
-	updateNode, err = utils.WriteSwitchdevConfFile(state)
+	// The switchdev device config file drives the per-VF udev rules that rename VF representors,
+	// so it only matters once VFs (and the network stack) exist; skip it in the pre-network phase.
+	if !p.SkipVFConfiguration {
+		updateNode, err = utils.WriteSwitchdevConfFile(state)
+		if err != nil {
+			log.Log.Error(err, "generic-plugin needRebootNode(): fail to write switchdev device config file")
+			return false, err
+		}
+		if updateNode {
+			log.Log.V(2).Info("generic-plugin needRebootNode(): need reboot for updating switchdev device configuration")
+			needReboot = true
+		}
+	}
+
+	rdmaModeReboot, err := p.needRdmaSubsystemModeChange(state)
 	if err != nil {
-		log.Log.Error(err, "generic-plugin needRebootNode(): fail to write switchdev device config file")
+		log.Log.Error(err, "generic-plugin needRebootNode(): failed to check RDMA subsystem mode")
 		return false, err
 	}
-	if updateNode {
-		log.Log.V(2).Info("generic-plugin needRebootNode(): need reboot for updating switchdev device configuration")
+	if rdmaModeReboot {
 		needReboot = true
 	}
 
 	return needReboot, nil
 }
 
+// needRdmaSubsystemModeChange checks whether the requested RDMA subsystem network-namespace mode
+// (System.RdmaMode) differs from the mode currently running on the host. Switching modes requires
+// all RDMA users to be released first, so a reboot is the only safe way to apply the change.
+func (p *GenericPlugin) needRdmaSubsystemModeChange(state *sriovnetworkv1.SriovNetworkNodeState) (bool, error) {
+	desiredMode := state.Spec.System.RdmaMode
+	if desiredMode == "" {
+		return false, nil
+	}
+
+	currentMode, err := p.HostManager.GetRDMASubsystemMode()
+	if err != nil {
+		return false, err
+	}
+
+	if !strings.EqualFold(desiredMode, currentMode) {
+		log.Log.V(2).Info("generic-plugin needRdmaSubsystemModeChange(): need reboot for RDMA subsystem mode change",
+			"current", currentMode, "desired", desiredMode)
+		return true, nil
+	}
+
+	return false, nil
+}
+
 // ////////////// for testing purposes only ///////////////////////
 func (p *GenericPlugin) getDriverStateMap() DriverStateMapType {
 	return p.DriverStateMap
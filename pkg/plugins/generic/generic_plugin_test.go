@@ -4,9 +4,61 @@ import (
 	"testing"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/featuregates"
 	"github.com/stretchr/testify/assert"
 )
 
+// fakeOVSManager counts calls instead of talking to a real OVSDB, so tests can assert
+// syncBridges() left it alone when the feature gate is disabled.
+type fakeOVSManager struct {
+	calls int
+}
+
+func (f *fakeOVSManager) GetOVSBridges() ([]sriovnetworkv1.OVSBridge, error) {
+	f.calls++
+	return nil, nil
+}
+
+func (f *fakeOVSManager) EnsureBridge(_ *sriovnetworkv1.OVSBridge) error {
+	f.calls++
+	return nil
+}
+
+func (f *fakeOVSManager) RemoveBridge(_ string) error {
+	f.calls++
+	return nil
+}
+
+func (f *fakeOVSManager) AttachUplink(_, _ string, _ sriovnetworkv1.OVSUplinkConfig) error {
+	f.calls++
+	return nil
+}
+
+func TestSyncBridgesSkippedWhenFeatureGateDisabled(t *testing.T) {
+	featuregates.Replace(nil)
+	ovsManager := &fakeOVSManager{}
+	p := &GenericPlugin{
+		OVSManager:  ovsManager,
+		DesireState: &sriovnetworkv1.SriovNetworkNodeState{},
+	}
+
+	assert.NoError(t, p.syncBridges())
+	assert.Equal(t, 0, ovsManager.calls)
+}
+
+func TestSyncBridgesRunsWhenFeatureGateEnabled(t *testing.T) {
+	featuregates.Replace(map[string]bool{featuregates.ManageSoftwareBridges: true})
+	t.Cleanup(func() { featuregates.Replace(nil) })
+	ovsManager := &fakeOVSManager{}
+	p := &GenericPlugin{
+		OVSManager:  ovsManager,
+		DesireState: &sriovnetworkv1.SriovNetworkNodeState{},
+	}
+
+	assert.NoError(t, p.syncBridges())
+	assert.Equal(t, 1, ovsManager.calls)
+}
+
 func TestNeedDrainNode_NoNeedToDrain(t *testing.T) {
 	desired := sriovnetworkv1.Interfaces{{
 		PciAddress: "0000:00:00.0",
@@ -0,0 +1,86 @@
+package generic
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+// PrerequisitesMetConditionType is pushed onto SriovNetworkNodeState.Status.Conditions to report
+// whether the kernel modules and userland tooling a requested policy needs are actually present,
+// so a missing one surfaces here instead of as a cryptic modprobe failure deep in syncDriverState.
+const PrerequisitesMetConditionType = "PrerequisitesMet"
+
+// checkPrerequisites validates, without attempting to install or load anything, that every kernel
+// module and piece of userland tooling requested by state.Spec is actually available on this node,
+// and records the result as a PrerequisitesMet condition on state.Status. Getting rdma-core or a
+// missing kernel module installed is the admin's job, the same call made by CheckRDMAEnabled.
+func (p *GenericPlugin) checkPrerequisites(state *sriovnetworkv1.SriovNetworkNodeState) {
+	reason, message := p.missingPrerequisite(state)
+	if reason != "" {
+		log.Log.Info("generic-plugin checkPrerequisites(): prerequisites not met", "reason", reason, "message", message)
+		setPrerequisitesCondition(state, metav1.ConditionFalse, reason, message)
+		return
+	}
+	setPrerequisitesCondition(state, metav1.ConditionTrue, "Satisfied", "all requested kernel modules and userland tooling are present")
+}
+
+// missingPrerequisite returns the reason/message for the first unmet prerequisite found, or ""/""
+// if everything requested by state is available.
+func (p *GenericPlugin) missingPrerequisite(state *sriovnetworkv1.SriovNetworkNodeState) (reason string, message string) {
+	for _, driverState := range p.DriverStateMap {
+		if driverState.DriverLoaded || !driverState.NeedDriverFunc(state, driverState) {
+			continue
+		}
+		available, err := p.HostManager.IsKernelModuleAvailable(driverState.DriverName)
+		if err != nil {
+			return "KernelModuleCheckFailed", fmt.Sprintf("failed to check whether kernel module %s is available: %v", driverState.DriverName, err)
+		}
+		if !available {
+			return "KernelModuleMissing", fmt.Sprintf("kernel module %s is required but not available on this node", driverState.DriverName)
+		}
+	}
+
+	if !needsRDMA(state) {
+		return "", ""
+	}
+
+	rdmaAvailable, err := p.HostManager.CheckRDMAUserlandAvailable()
+	if err != nil {
+		return "RdmaCheckFailed", fmt.Sprintf("failed to check rdma-core prerequisites: %v", err)
+	}
+	if !rdmaAvailable {
+		return "RdmaCoreMissing", "RDMA is requested by a VF group but /sys/class/infiniband or the ibv_devices userland tool is not present on this node"
+	}
+
+	return "", ""
+}
+
+// needsRDMA reports whether any interface in state.Spec requests an RDMA-enabled VF group
+func needsRDMA(state *sriovnetworkv1.SriovNetworkNodeState) bool {
+	for _, iface := range state.Spec.Interfaces {
+		for i := range iface.VfGroups {
+			if iface.VfGroups[i].IsRdma {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setPrerequisitesCondition sets the PrerequisitesMet condition on state.Status, replacing any
+// earlier one of the same type. Going through meta.SetStatusCondition instead of hand-rolling this
+// gets LastTransitionTime right (only bumped when Status actually changes) and leaves it untouched
+// on repeated calls that report the same status, instead of a bare overwrite losing that semantic.
+func setPrerequisitesCondition(state *sriovnetworkv1.SriovNetworkNodeState, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&state.Status.Conditions, metav1.Condition{
+		Type:    PrerequisitesMetConditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
@@ -0,0 +1,106 @@
+package generic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
+)
+
+// fakeHostManagerForPrerequisites implements host.HostManagerInterface, embedding a nil interface
+// so only the methods checkPrerequisites actually calls need to be overridden.
+type fakeHostManagerForPrerequisites struct {
+	host.HostManagerInterface
+	rdmaUserlandAvailable bool
+	rdmaUserlandErr       error
+}
+
+func (f *fakeHostManagerForPrerequisites) CheckRDMAUserlandAvailable() (bool, error) {
+	return f.rdmaUserlandAvailable, f.rdmaUserlandErr
+}
+
+func stateWithRdmaGroup(isRdma bool) *sriovnetworkv1.SriovNetworkNodeState {
+	return &sriovnetworkv1.SriovNetworkNodeState{
+		Spec: sriovnetworkv1.SriovNetworkNodeStateSpec{
+			Interfaces: []sriovnetworkv1.Interface{
+				{
+					VfGroups: []sriovnetworkv1.VfGroup{
+						{IsRdma: isRdma},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestNeedsRDMAFalseWhenNoGroupRequestsRdma(t *testing.T) {
+	assert.False(t, needsRDMA(stateWithRdmaGroup(false)))
+}
+
+func TestNeedsRDMATrueWhenGroupRequestsRdma(t *testing.T) {
+	assert.True(t, needsRDMA(stateWithRdmaGroup(true)))
+}
+
+func TestCheckPrerequisitesSatisfiedWhenRdmaUserlandAvailable(t *testing.T) {
+	p := &GenericPlugin{
+		DriverStateMap: DriverStateMapType{},
+		HostManager:    &fakeHostManagerForPrerequisites{rdmaUserlandAvailable: true},
+	}
+	state := stateWithRdmaGroup(true)
+
+	p.checkPrerequisites(state)
+
+	require.Len(t, state.Status.Conditions, 1)
+	assert.Equal(t, PrerequisitesMetConditionType, state.Status.Conditions[0].Type)
+	assert.Equal(t, metav1.ConditionTrue, state.Status.Conditions[0].Status)
+}
+
+func TestCheckPrerequisitesReportsMissingRdmaUserland(t *testing.T) {
+	p := &GenericPlugin{
+		DriverStateMap: DriverStateMapType{},
+		HostManager:    &fakeHostManagerForPrerequisites{rdmaUserlandAvailable: false},
+	}
+	state := stateWithRdmaGroup(true)
+
+	p.checkPrerequisites(state)
+
+	require.Len(t, state.Status.Conditions, 1)
+	assert.Equal(t, PrerequisitesMetConditionType, state.Status.Conditions[0].Type)
+	assert.Equal(t, metav1.ConditionFalse, state.Status.Conditions[0].Status)
+	assert.Equal(t, "RdmaCoreMissing", state.Status.Conditions[0].Reason)
+}
+
+func TestCheckPrerequisitesReplacesExistingCondition(t *testing.T) {
+	p := &GenericPlugin{
+		DriverStateMap: DriverStateMapType{},
+		HostManager:    &fakeHostManagerForPrerequisites{rdmaUserlandAvailable: true},
+	}
+	state := stateWithRdmaGroup(true)
+	state.Status.Conditions = []metav1.Condition{
+		{Type: PrerequisitesMetConditionType, Status: metav1.ConditionFalse, Reason: "RdmaCoreMissing"},
+	}
+
+	p.checkPrerequisites(state)
+
+	require.Len(t, state.Status.Conditions, 1)
+	assert.Equal(t, metav1.ConditionTrue, state.Status.Conditions[0].Status)
+}
+
+func TestCheckPrerequisitesReportsCheckFailure(t *testing.T) {
+	p := &GenericPlugin{
+		DriverStateMap: DriverStateMapType{},
+		HostManager:    &fakeHostManagerForPrerequisites{rdmaUserlandErr: errors.New("stat failed")},
+	}
+	state := stateWithRdmaGroup(true)
+
+	p.checkPrerequisites(state)
+
+	require.Len(t, state.Status.Conditions, 1)
+	assert.Equal(t, metav1.ConditionFalse, state.Status.Conditions[0].Status)
+	assert.Equal(t, "RdmaCheckFailed", state.Status.Conditions[0].Reason)
+}
@@ -2,16 +2,24 @@ package mellanox
 
 import (
 	"fmt"
+	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/consts"
 	plugin "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/plugins"
 	mlx "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vendors/mellanox"
 )
 
 var PluginName = "mellanox_plugin"
 
+// MellanoxPlugin only ever touches NIC firmware parameters, which are PF-level and always require
+// a reboot to take effect, so unlike the generic plugin it has nothing that needs deferring to the
+// post-network phase: it is safe to run as-is in PhasePre (sriov-config.service),
+// PhasePost (sriov-config-post-network.service) or the long-running daemon alike, and needs no
+// SkipVFConfiguration-style option.
 type MellanoxPlugin struct {
 	PluginName  string
 	SpecVersion string
@@ -53,6 +61,7 @@ func (p *MellanoxPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeS
 	attributesToChange = map[string]mlx.MlxNic{}
 	mellanoxNicsSpec = map[string]sriovnetworkv1.Interface{}
 	processedNics := map[string]bool{}
+	externallyManagedPfs := getExternallyManagedPfs(new)
 
 	// Read mellanox NIC status once
 	if len(mellanoxNicsStatus) == 0 {
@@ -95,6 +104,14 @@ func (p *MellanoxPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeS
 			continue
 		}
 		processedNics[pciPrefix] = true
+
+		// PFs provisioned outside the operator must never have their firmware touched, regardless
+		// of what the policy spec or the status drift detection below would otherwise compute
+		if ifaceSpec.ExternallyManaged || externallyManagedPfs[pciPrefix] {
+			log.Log.V(2).Info("mellanox-plugin: skipping externally managed PF", "pciAddress", ifaceSpec.PciAddress)
+			continue
+		}
+
 		fwCurrent, fwNext, err := p.helpers.GetMlxNicFwData(ifaceSpec.PciAddress)
 		if err != nil {
 			return false, false, err
@@ -111,21 +128,26 @@ func (p *MellanoxPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeS
 		needReboot = needReboot || sriovEnNeedReboot
 		changeWithoutReboot = changeWithoutReboot || sriovEnChangeWithoutReboot
 
-		// failing as we can't the fwTotalVf is lower than the request one on a nic with externallyManage configured
-		if ifaceSpec.ExternallyManaged && needReboot {
-			return true, true, fmt.Errorf(
-				"interface %s required a change in the TotalVfs but the policy is externally managed failing: firmware TotalVf %d requested TotalVf %d",
-				ifaceSpec.PciAddress, fwCurrent.TotalVfs, totalVfs)
-		}
-
 		needLinkChange, err := mlx.HandleLinkType(pciPrefix, fwCurrent, attrs, mellanoxNicsSpec, mellanoxNicsStatus)
 		if err != nil {
 			return false, false, err
 		}
 
 		needReboot = needReboot || needLinkChange
+
+		configNeedReboot, configChangeWithoutReboot := mlx.HandleMellanoxConfig(fwCurrent, attrs, ifaceSpec.MellanoxConfig)
+		needReboot = needReboot || configNeedReboot
+		changeWithoutReboot = changeWithoutReboot || configChangeWithoutReboot
+
 		if needReboot || changeWithoutReboot {
 			attributesToChange[ifaceSpec.PciAddress] = *attrs
+			pendingChanges := mlx.BuildPendingChanges(ifaceSpec.PciAddress, fwCurrent, *attrs)
+			new.Status.PendingFirmwareChanges = append(new.Status.PendingFirmwareChanges, pendingChanges...)
+			for _, change := range pendingChanges {
+				p.helpers.RecordEvent(new, corev1.EventTypeNormal, "PendingFirmwareChange",
+					fmt.Sprintf("%s: %s %s -> %s (requiresReboot=%t)",
+						change.PciAddress, change.Attribute, change.Current, change.Desired, change.RequiresReboot))
+			}
 		}
 	}
 
@@ -139,6 +161,12 @@ func (p *MellanoxPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeS
 		processedNics[pciPrefix] = true
 		pciAddress := pciPrefix + "0"
 
+		// Never zero out TotalVfs on a PF the admin declared externally managed via annotation
+		if externallyManagedPfs[pciPrefix] {
+			log.Log.V(2).Info("mellanox-plugin: skipping externally managed PF with no spec", "pciAddress", pciAddress)
+			continue
+		}
+
 		// Skip unsupported devices
 		if id := sriovnetworkv1.GetVfDeviceID(portsMap[pciAddress].DeviceID); id == "" {
 			continue
@@ -162,6 +190,20 @@ func (p *MellanoxPlugin) OnNodeStateChange(new *sriovnetworkv1.SriovNetworkNodeS
 	return
 }
 
+// getExternallyManagedPfs reads the comma-separated PCI address prefixes listed in the
+// ExternallyManagedPfsAnnotation on the NodeState CR. This covers PFs provisioned outside the
+// operator that never get a policy spec of their own, so ifaceSpec.ExternallyManaged can't reach them.
+func getExternallyManagedPfs(new *sriovnetworkv1.SriovNetworkNodeState) map[string]bool {
+	result := map[string]bool{}
+	for _, pciPrefix := range strings.Split(new.Annotations[consts.ExternallyManagedPfsAnnotation], ",") {
+		pciPrefix = strings.TrimSpace(pciPrefix)
+		if pciPrefix != "" {
+			result[pciPrefix] = true
+		}
+	}
+	return result
+}
+
 // Apply config change
 func (p *MellanoxPlugin) Apply() error {
 	if p.helpers.IsKernelLockdownMode() {
@@ -169,5 +211,11 @@ func (p *MellanoxPlugin) Apply() error {
 		return nil
 	}
 	log.Log.Info("mellanox-plugin Apply()")
-	return p.helpers.MlxConfigFW(attributesToChange)
+	if err := p.helpers.MlxConfigFW(attributesToChange); err != nil {
+		return err
+	}
+
+	// Reset the firmware of every PF whose pending changes are marked NeedFWReset (a VF count or
+	// firmware-reset-only attribute change), so they take effect without a node reboot
+	return p.helpers.MlxResetFW(attributesToChange)
 }
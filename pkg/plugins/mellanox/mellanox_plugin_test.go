@@ -0,0 +1,28 @@
+package mellanox
+
+import (
+	"testing"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/global/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExternallyManagedPfs_NoAnnotation(t *testing.T) {
+	nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+
+	assert.Empty(t, getExternallyManagedPfs(nodeState))
+}
+
+func TestGetExternallyManagedPfs_MixedClusterAllowlist(t *testing.T) {
+	nodeState := &sriovnetworkv1.SriovNetworkNodeState{}
+	nodeState.Annotations = map[string]string{
+		consts.ExternallyManagedPfsAnnotation: "0000:d8:00., 0000:d8:00.",
+	}
+
+	result := getExternallyManagedPfs(nodeState)
+
+	assert.True(t, result["0000:d8:00."])
+	assert.Len(t, result, 1)
+	assert.False(t, result["0000:ca:00."])
+}
@@ -1,10 +1,13 @@
 package plugin
 
 import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/host"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/ovs"
 	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/utils"
 	mlx "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vendors/mellanox"
 )
@@ -26,6 +29,10 @@ type HostHelpersInterface interface {
 	host.HostManagerInterface
 	host.StoreManagerInterface
 	mlx.MellanoxInterface
+	ovs.Interface
+	// RecordEvent emits a Kubernetes event against obj describing a plugin-driven change (e.g. a
+	// pending firmware reset/reboot). It is a no-op if no event recorder was configured.
+	RecordEvent(obj runtime.Object, eventType, reason, message string)
 }
 
 type HostHelpers struct {
@@ -33,20 +40,33 @@ type HostHelpers struct {
 	host.HostManagerInterface
 	host.StoreManagerInterface
 	mlx.MellanoxInterface
+	ovs.Interface
+
+	eventRecorder record.EventRecorder
+}
+
+func (h *HostHelpers) RecordEvent(obj runtime.Object, eventType, reason, message string) {
+	if h.eventRecorder == nil {
+		return
+	}
+	h.eventRecorder.Event(obj, eventType, reason, message)
 }
 
 // Use for unit tests
 func NewVendorPluginHelpers(utilsHelper utils.UtilsInterface,
 	hostManager host.HostManagerInterface,
 	storeManager host.StoreManagerInterface,
-	mlxHelper mlx.MellanoxInterface) *HostHelpers {
-	return &HostHelpers{utilsHelper, hostManager, storeManager, mlxHelper}
+	mlxHelper mlx.MellanoxInterface,
+	ovsHelper ovs.Interface,
+	eventRecorder record.EventRecorder) *HostHelpers {
+	return &HostHelpers{utilsHelper, hostManager, storeManager, mlxHelper, ovsHelper, eventRecorder}
 }
 
-func NewDefaultVendorPluginHelpers() (*HostHelpers, error) {
+func NewDefaultVendorPluginHelpers(eventRecorder record.EventRecorder) (*HostHelpers, error) {
 	utilsHelper := utils.NewUtilsHelper()
 	mlxHelper := mlx.New(utilsHelper)
 	hostManager := host.NewHostManager(utilsHelper)
+	ovsHelper := ovs.New()
 	storeManager, err := host.NewStoreManager()
 	if err != nil {
 		log.Log.Error(err, "failed to create store manager")
@@ -57,5 +77,7 @@ func NewDefaultVendorPluginHelpers() (*HostHelpers, error) {
 		utilsHelper,
 		hostManager,
 		storeManager,
-		mlxHelper}, nil
+		mlxHelper,
+		ovsHelper,
+		eventRecorder}, nil
 }
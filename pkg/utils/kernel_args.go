@@ -0,0 +1,9 @@
+package utils
+
+// KernelArgAmdIommu enables the AMD IOMMU. It's the AMD equivalent of KernelArgIntelIommu, needed
+// on AMD EPYC/Ryzen hosts where intel_iommu=on is a no-op.
+const KernelArgAmdIommu = "amd_iommu=on"
+
+// KernelArgArmSmmu enables the ARM SMMU. It's the ARM equivalent of KernelArgIntelIommu, needed on
+// ARM platforms where there's no Intel or AMD IOMMU to enable.
+const KernelArgArmSmmu = "arm-smmu.disable_bypass=0"
@@ -51,6 +51,10 @@ var (
 	// FilesystemRoot used by test to mock interactions with filesystem
 	FilesystemRoot = ""
 
+	// OVSDBSocketPath is the unix socket path of the local OVSDB server; no flag in this
+	// repository sets it
+	OVSDBSocketPath = "/var/run/openvswitch/db.sock"
+
 	//Cluster variables
 	Config *rest.Config    = nil
 	Scheme *runtime.Scheme = nil
@@ -0,0 +1,38 @@
+// Package dpu is a starting point for SmartNIC/DPU vendors (e.g. Yusur, BlueField-style cards)
+// whose VFs aren't plain PCI children of the PF and so need a different PF-PCI -> VF-PCI mapping
+// than dputils.GetVFList provides. It is not wired up to a real vendor ID yet; vendor-specific
+// forks/out-of-tree builds are expected to copy this package, fill in ResolveVFPCI, and call
+// vendors.Register with their device's PCI vendor ID from an init().
+package dpu
+
+import (
+	"fmt"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vendors"
+)
+
+// handler is a template vendors.VendorHandler for DPU-style NICs. Unlike the Mellanox handler,
+// ResolveVFPCI can't fall back to dputils.GetVFList because a DPU's VFs may be fronted by an
+// internal switch rather than exposed as direct PCI children of the PF.
+type handler struct{}
+
+var _ vendors.VendorHandler = (*handler)(nil)
+
+func (h *handler) PreConfig(ifaceStatuses []sriovnetworkv1.InterfaceExt, interfaces []sriovnetworkv1.Interface, kernelLockdownMode bool) error {
+	return nil
+}
+
+func (h *handler) ConfigureVF(pfAddr, vfAddr string, group *sriovnetworkv1.VfGroup) error {
+	return nil
+}
+
+// ResolveVFPCI is unimplemented here; a real DPU handler must replace this with a mapping
+// appropriate to that vendor's VF topology.
+func (h *handler) ResolveVFPCI(pfAddr string, vfIndex int) (string, error) {
+	return "", fmt.Errorf("dpu: ResolveVFPCI not implemented for PF %s, vf %d", pfAddr, vfIndex)
+}
+
+func (h *handler) PostConfig(ifaceStatuses []sriovnetworkv1.InterfaceExt, interfaces []sriovnetworkv1.Interface) error {
+	return nil
+}
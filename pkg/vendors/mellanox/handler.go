@@ -0,0 +1,56 @@
+package mellanox
+
+import (
+	"fmt"
+
+	dputils "github.com/k8snetworkplumbingwg/sriov-network-device-plugin/pkg/utils"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/vendors"
+)
+
+// handler implements vendors.VendorHandler for Mellanox/NVIDIA PFs. VF PCI addresses are plain
+// PCI children of the PF, same as the stock dputils-based resolution, and firmware attributes are
+// already applied by the Mellanox vendor plugin, so ConfigureVF/PostConfig are no-ops here; only
+// the kernel-lockdown guard needs a hook.
+type handler struct{}
+
+func init() {
+	vendors.Register(MellanoxVendorID, &handler{})
+}
+
+// PreConfig refuses to configure Mellanox PFs while the kernel is in lockdown mode, since lockdown
+// blocks the mlxconfig/mlxfwreset operations the Mellanox plugin relies on.
+func (h *handler) PreConfig(ifaceStatuses []sriovnetworkv1.InterfaceExt, interfaces []sriovnetworkv1.Interface, kernelLockdownMode bool) error {
+	if kernelLockdownMode && HasMellanoxInterfacesInSpec(ifaceStatuses, interfaces) {
+		return fmt.Errorf("cannot use mellanox devices when in kernel lockdown mode")
+	}
+	return nil
+}
+
+// ConfigureVF is a no-op: Mellanox VFs don't need configuration beyond what HostManager already
+// applies generically (admin MAC, MTU, driver bind).
+func (h *handler) ConfigureVF(pfAddr, vfAddr string, group *sriovnetworkv1.VfGroup) error {
+	return nil
+}
+
+// ResolveVFPCI returns the VF's PCI address the same way the stock (non-vendor-specific) path
+// does: Mellanox VFs are plain PCI children of the PF.
+func (h *handler) ResolveVFPCI(pfAddr string, vfIndex int) (string, error) {
+	vfAddrs, err := dputils.GetVFList(pfAddr)
+	if err != nil {
+		return "", err
+	}
+	for _, addr := range vfAddrs {
+		id, err := dputils.GetVFID(addr)
+		if err == nil && id == vfIndex {
+			return addr, nil
+		}
+	}
+	return "", nil
+}
+
+// PostConfig is a no-op for Mellanox; nothing needs to run once per sync after every PF is done.
+func (h *handler) PostConfig(ifaceStatuses []sriovnetworkv1.InterfaceExt, interfaces []sriovnetworkv1.Interface) error {
+	return nil
+}
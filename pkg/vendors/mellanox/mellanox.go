@@ -0,0 +1,344 @@
+package mlxutils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+)
+
+// MellanoxVendorID is the PCI vendor ID for Mellanox/NVIDIA networking devices
+const MellanoxVendorID = "15b3"
+
+const (
+	totalVfsConfigName    = "NUM_OF_VFS"
+	enableSriovConfigName = "SRIOV_EN"
+	linkTypeConfigName    = "LINK_TYPE_P1"
+
+	linkTypeEth  = "ETH"
+	linkTypeInfi = "IB"
+)
+
+// MlxNic holds the set of mlxconfig attributes this package knows how to read and diff. TotalVfs
+// == -1 and an empty LinkType mean "no change requested" for those attributes; SRIOV_EN is always
+// written by MlxConfigFW with EnableSriov's value.
+type MlxNic struct {
+	TotalVfs    int
+	EnableSriov bool
+	LinkType    string
+	// NeedFWReset indicates the pending TotalVfs change can be applied by resetting the device
+	// firmware (mstfwreset) instead of rebooting the whole node.
+	NeedFWReset bool
+	// ExtraAttrs holds arbitrary mlxconfig attribute values beyond the well-known ones above,
+	// keyed by mlxconfig attribute name (e.g. "ROCE_CC_PRIO_MASK_P1"). Populated from
+	// Interface.MellanoxConfig on the desired side and from mlxconfig query on the firmware side.
+	ExtraAttrs map[string]string
+}
+
+// PendingFWChange describes a single mlxconfig attribute that differs between the firmware's
+// current value and the value the operator is about to apply, so it can be surfaced to admins via
+// SriovNetworkNodeState.Status and Kubernetes events before the change is made.
+type PendingFWChange struct {
+	PciAddress     string
+	Attribute      string
+	Current        string
+	Desired        string
+	RequiresReboot bool
+}
+
+// BuildPendingChanges diffs attrs (the change this package is about to apply) against fwCurrent and
+// returns one PendingFWChange per attribute that differs.
+func BuildPendingChanges(pciAddress string, fwCurrent, attrs MlxNic) []PendingFWChange {
+	var changes []PendingFWChange
+
+	add := func(attribute, current, desired string) {
+		changes = append(changes, PendingFWChange{
+			PciAddress:     pciAddress,
+			Attribute:      attribute,
+			Current:        current,
+			Desired:        desired,
+			RequiresReboot: !attrs.NeedFWReset,
+		})
+	}
+
+	if attrs.TotalVfs >= 0 && attrs.TotalVfs != fwCurrent.TotalVfs {
+		add(totalVfsConfigName, strconv.Itoa(fwCurrent.TotalVfs), strconv.Itoa(attrs.TotalVfs))
+	}
+	if attrs.EnableSriov != fwCurrent.EnableSriov {
+		add(enableSriovConfigName, sriovEnValue(fwCurrent.EnableSriov), sriovEnValue(attrs.EnableSriov))
+	}
+	if attrs.LinkType != "" && attrs.LinkType != fwCurrent.LinkType {
+		add(linkTypeConfigName, fwCurrent.LinkType, attrs.LinkType)
+	}
+	for attr, desired := range attrs.ExtraAttrs {
+		add(attr, fwCurrent.ExtraAttrs[attr], desired)
+	}
+
+	return changes
+}
+
+// AttrMetadata describes how an mlxconfig attribute change needs to be applied
+type AttrMetadata struct {
+	// RequiresReboot means the change only takes effect after the node reboots
+	RequiresReboot bool
+	// RequiresFWReset means the change takes effect after an mstfwreset, without a node reboot
+	RequiresFWReset bool
+}
+
+// KnownMellanoxConfigAttrs declares the apply semantics of the extra mlxconfig attributes
+// supported via Interface.MellanoxConfig. An attribute absent from this map is conservatively
+// treated as requiring a reboot.
+var KnownMellanoxConfigAttrs = map[string]AttrMetadata{
+	"ROCE_CC_PRIO_MASK_P1":     {RequiresFWReset: true},
+	"UCTX_EN":                  {RequiresReboot: true},
+	"NUM_OF_VFS":               {RequiresFWReset: true},
+	"KEEP_ETH_LINK_UP_P1":      {RequiresFWReset: true},
+	"SRIOV_IB_ROUTING_MODE_P1": {RequiresReboot: true},
+}
+
+// CmdInterface is the minimal command-execution surface MstConfigReadData and MlxResetFW need,
+// satisfied by utils.UtilsInterface in production and stubbed out in unit tests.
+type CmdInterface interface {
+	RunCommand(cmd string, args ...string) (string, string, error)
+	Chroot(path string) (func() error, error)
+}
+
+// MellanoxInterface exposes the Mellanox-specific firmware operations consumed by the mellanox plugin
+//
+//go:generate ../../../bin/mockgen -destination mock/mock_mellanox.go -source mellanox.go
+type MellanoxInterface interface {
+	// GetMlxNicFwData reads the current and next-boot mlxconfig values for the PF at pciAddress
+	GetMlxNicFwData(pciAddress string) (current, next MlxNic, err error)
+	// MlxConfigFW applies the attribute changes in attributesToChange via mlxconfig set
+	MlxConfigFW(attributesToChange map[string]MlxNic) error
+	// MlxResetFW resets the device firmware so pending mlxconfig changes take effect without a node reboot
+	MlxResetFW(attributesToChange map[string]MlxNic) error
+}
+
+type mlxHelper struct {
+	cmd CmdInterface
+}
+
+// New returns a MellanoxInterface backed by cmd for running mst/mlxconfig commands
+func New(cmd CmdInterface) *mlxHelper {
+	return &mlxHelper{cmd: cmd}
+}
+
+// MstConfigReadData runs `mlxconfig -e -d <pciAddress> query` and returns its raw key/value output
+func (h *mlxHelper) MstConfigReadData(pciAddress string) (map[string]string, error) {
+	log.Log.V(2).Info("MstConfigReadData()", "pciAddress", pciAddress)
+	out, _, err := h.cmd.RunCommand("mlxconfig", "-e", "-d", pciAddress, "query")
+	if err != nil {
+		return nil, fmt.Errorf("MstConfigReadData(): failed to query device %s: %v", pciAddress, err)
+	}
+
+	data := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		data[fields[0]] = fields[len(fields)-1]
+	}
+
+	return data, nil
+}
+
+func (h *mlxHelper) GetMlxNicFwData(pciAddress string) (MlxNic, MlxNic, error) {
+	data, err := h.MstConfigReadData(pciAddress)
+	if err != nil {
+		return MlxNic{}, MlxNic{}, err
+	}
+
+	parse := func() MlxNic {
+		nic := MlxNic{TotalVfs: -1}
+		if v, ok := data[totalVfsConfigName]; ok {
+			if n, err := strconv.Atoi(v); err == nil {
+				nic.TotalVfs = n
+			}
+		}
+		nic.EnableSriov = data[enableSriovConfigName] == "True(1)"
+		nic.LinkType = data[linkTypeConfigName]
+		nic.ExtraAttrs = map[string]string{}
+		for attr := range KnownMellanoxConfigAttrs {
+			if v, ok := data[attr]; ok {
+				nic.ExtraAttrs[attr] = v
+			}
+		}
+		return nic
+	}
+
+	current := parse()
+	next := parse()
+	return current, next, nil
+}
+
+func (h *mlxHelper) MlxConfigFW(attributesToChange map[string]MlxNic) error {
+	for pciAddress, attrs := range attributesToChange {
+		args := []string{"-e", "-d", pciAddress, "-y", "set"}
+		if attrs.TotalVfs >= 0 {
+			args = append(args, fmt.Sprintf("%s=%d", totalVfsConfigName, attrs.TotalVfs))
+		}
+		if attrs.LinkType != "" {
+			args = append(args, fmt.Sprintf("%s=%s", linkTypeConfigName, attrs.LinkType))
+		}
+		args = append(args, fmt.Sprintf("%s=%s", enableSriovConfigName, sriovEnValue(attrs.EnableSriov)))
+		for attr, value := range attrs.ExtraAttrs {
+			args = append(args, fmt.Sprintf("%s=%s", attr, value))
+		}
+
+		log.Log.Info("MlxConfigFW(): applying mlxconfig changes", "pciAddress", pciAddress)
+		if _, _, err := h.cmd.RunCommand("mlxconfig", args...); err != nil {
+			return fmt.Errorf("MlxConfigFW(): failed to configure device %s: %v", pciAddress, err)
+		}
+	}
+	return nil
+}
+
+// MlxResetFW resets the firmware of every PF in attributesToChange that was marked with NeedFWReset,
+// so the mlxconfig changes already written by MlxConfigFW take effect without requiring a node reboot.
+func (h *mlxHelper) MlxResetFW(attributesToChange map[string]MlxNic) error {
+	for pciAddress, attrs := range attributesToChange {
+		if !attrs.NeedFWReset {
+			continue
+		}
+
+		log.Log.Info("MlxResetFW(): resetting device firmware", "pciAddress", pciAddress)
+		if _, _, err := h.cmd.RunCommand("mstfwreset", "-d", pciAddress, "-y", "reset"); err != nil {
+			return fmt.Errorf("MlxResetFW(): failed to reset firmware for device %s: %v", pciAddress, err)
+		}
+	}
+	return nil
+}
+
+func sriovEnValue(enabled bool) string {
+	if enabled {
+		return "True(1)"
+	}
+	return "False(0)"
+}
+
+// GetPciAddressPrefix returns the PCI address of a device with the function index stripped off, so
+// both ports of a dual-port card share the same key
+func GetPciAddressPrefix(pciAddress string) string {
+	return pciAddress[:len(pciAddress)-1]
+}
+
+// IsDualPort returns whether the PF at pciAddress has a sibling port reported in nicsStatusMap
+func IsDualPort(pciAddress string, nicsStatusMap map[string]map[string]sriovnetworkv1.InterfaceExt) bool {
+	return len(nicsStatusMap[GetPciAddressPrefix(pciAddress)]) > 1
+}
+
+// HasMellanoxInterfacesInSpec returns whether any interface requested in spec belongs to a Mellanox PF
+func HasMellanoxInterfacesInSpec(ifaceStatuses []sriovnetworkv1.InterfaceExt, interfaces []sriovnetworkv1.Interface) bool {
+	statusByAddress := map[string]sriovnetworkv1.InterfaceExt{}
+	for _, s := range ifaceStatuses {
+		statusByAddress[s.PciAddress] = s
+	}
+
+	for _, iface := range interfaces {
+		if status, ok := statusByAddress[iface.PciAddress]; ok && status.Vendor == MellanoxVendorID {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleTotalVfs decides whether ifaceSpec's requested number of VFs differs from the firmware's
+// current value, filling attrs and returning the resolved VF count along with whether applying it
+// requires a full node reboot or can be applied live (changeWithoutReboot).
+func HandleTotalVfs(fwCurrent, fwNext MlxNic, attrs *MlxNic, ifaceSpec sriovnetworkv1.Interface,
+	isDualPort bool, nicsSpecMap map[string]sriovnetworkv1.Interface) (totalVfs int, needReboot bool, changeWithoutReboot bool) {
+	totalVfs = ifaceSpec.NumVfs
+
+	if isDualPort {
+		if peer, ok := nicsSpecMap[GetPciAddressPrefix(ifaceSpec.PciAddress)+"1"]; ok && peer.NumVfs > totalVfs {
+			totalVfs = peer.NumVfs
+		}
+	}
+
+	if fwNext.TotalVfs == totalVfs {
+		return totalVfs, false, false
+	}
+
+	attrs.TotalVfs = totalVfs
+
+	// A VF count change alone can be applied by resetting the device firmware instead of rebooting
+	// the node, as long as the device isn't simultaneously flipping SRIOV_EN or LINK_TYPE.
+	attrs.NeedFWReset = true
+	return totalVfs, false, true
+}
+
+// HandleEnableSriov decides whether SRIOV_EN needs to change to match totalVfs, and disables the
+// firmware-reset fast path since enabling/disabling SRIOV itself requires a reboot.
+func HandleEnableSriov(totalVfs int, fwCurrent, fwNext MlxNic, attrs *MlxNic) (needReboot bool, changeWithoutReboot bool) {
+	desired := totalVfs > 0
+	// MlxConfigFW always writes SRIOV_EN, so attrs carries the desired value even when unchanged
+	attrs.EnableSriov = desired
+	if fwNext.EnableSriov == desired {
+		return false, false
+	}
+
+	attrs.NeedFWReset = false
+	return true, false
+}
+
+// HandleLinkType decides whether LINK_TYPE_P1 needs to change, and disables the firmware-reset fast
+// path since a link type flip requires a reboot.
+func HandleLinkType(pciPrefix string, fwCurrent MlxNic, attrs *MlxNic, nicsSpecMap map[string]sriovnetworkv1.Interface,
+	nicsStatusMap map[string]map[string]sriovnetworkv1.InterfaceExt) (needReboot bool, err error) {
+	ifaceSpec, ok := nicsSpecMap[pciPrefix+"0"]
+	if !ok || ifaceSpec.LinkType == "" {
+		return false, nil
+	}
+
+	desired := strings.ToUpper(ifaceSpec.LinkType)
+	if desired != linkTypeEth && desired != linkTypeInfi {
+		return false, fmt.Errorf("HandleLinkType(): unsupported link type %s for device %s", ifaceSpec.LinkType, pciPrefix)
+	}
+
+	if fwCurrent.LinkType == desired {
+		return false, nil
+	}
+
+	attrs.LinkType = desired
+	attrs.NeedFWReset = false
+	return true, nil
+}
+
+// HandleMellanoxConfig diffs the attributes requested via Interface.MellanoxConfig against fwCurrent
+// and fills attrs.ExtraAttrs with the ones that changed. It returns whether any of the changed
+// attributes require a reboot or can be applied via firmware reset, per KnownMellanoxConfigAttrs.
+func HandleMellanoxConfig(fwCurrent MlxNic, attrs *MlxNic, desired map[string]string) (needReboot bool, changeWithoutReboot bool) {
+	sawFWResetAttr := false
+	for attr, value := range desired {
+		if fwCurrent.ExtraAttrs[attr] == value {
+			continue
+		}
+
+		if attrs.ExtraAttrs == nil {
+			attrs.ExtraAttrs = map[string]string{}
+		}
+		attrs.ExtraAttrs[attr] = value
+
+		meta, known := KnownMellanoxConfigAttrs[attr]
+		switch {
+		case !known, meta.RequiresReboot:
+			needReboot = true
+		case meta.RequiresFWReset:
+			changeWithoutReboot = true
+			sawFWResetAttr = true
+		}
+	}
+
+	// Map iteration order is random, so a reboot-requiring attr and an FW-reset-only attr can be
+	// seen in either order above; always let needReboot win regardless of which was seen last, both
+	// in the returned bool and in attrs.NeedFWReset itself - the PF is rebooting anyway, so
+	// MlxResetFW must not run a needless mstfwreset against it.
+	// An earlier NeedFWReset (e.g. from HandleTotalVfs) is kept unless a reboot is now required.
+	attrs.NeedFWReset = (attrs.NeedFWReset || sawFWResetAttr) && !needReboot
+	return needReboot, changeWithoutReboot && !needReboot
+}
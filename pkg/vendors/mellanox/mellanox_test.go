@@ -15,16 +15,84 @@ func TestMstConfigReadData_ConnectX6(t *testing.T) {
 	sut.MstConfigReadData("0000:ca:00.0")
 }
 
+func TestHandleMellanoxConfig_OnlyFWResetAttr(t *testing.T) {
+	fwCurrent := MlxNic{ExtraAttrs: map[string]string{"ROCE_CC_PRIO_MASK_P1": "0"}}
+	attrs := &MlxNic{TotalVfs: -1}
+
+	needReboot, changeWithoutReboot := HandleMellanoxConfig(fwCurrent, attrs, map[string]string{"ROCE_CC_PRIO_MASK_P1": "255"})
+
+	assert.False(t, needReboot)
+	assert.True(t, changeWithoutReboot)
+	assert.True(t, attrs.NeedFWReset)
+	assert.Equal(t, "255", attrs.ExtraAttrs["ROCE_CC_PRIO_MASK_P1"])
+}
+
+func TestHandleMellanoxConfig_RebootAttrClearsNeedFWResetRegardlessOfOrder(t *testing.T) {
+	fwCurrent := MlxNic{ExtraAttrs: map[string]string{
+		"ROCE_CC_PRIO_MASK_P1": "0",
+		"UCTX_EN":              "0",
+	}}
+	attrs := &MlxNic{TotalVfs: -1}
+
+	// Map iteration order is random, so run enough times to cover both orderings of the
+	// FW-reset-only attr and the reboot-requiring attr.
+	for i := 0; i < 10; i++ {
+		attrs.NeedFWReset = false
+		needReboot, changeWithoutReboot := HandleMellanoxConfig(fwCurrent, attrs, map[string]string{
+			"ROCE_CC_PRIO_MASK_P1": "255",
+			"UCTX_EN":              "1",
+		})
+
+		assert.True(t, needReboot)
+		assert.False(t, changeWithoutReboot)
+		assert.False(t, attrs.NeedFWReset, "NeedFWReset must not survive when a reboot is also required, or MlxResetFW runs a needless mstfwreset on a rebooting PF")
+	}
+}
+
+func TestHandleMellanoxConfig_UnknownAttrRequiresReboot(t *testing.T) {
+	fwCurrent := MlxNic{ExtraAttrs: map[string]string{}}
+	attrs := &MlxNic{TotalVfs: -1, NeedFWReset: true}
+
+	needReboot, _ := HandleMellanoxConfig(fwCurrent, attrs, map[string]string{"SOME_FUTURE_ATTR": "1"})
+
+	assert.True(t, needReboot)
+	assert.False(t, attrs.NeedFWReset)
+}
+
+func TestBuildPendingChanges_ReportsChangedAttrsWithRebootFlag(t *testing.T) {
+	fwCurrent := MlxNic{TotalVfs: 8, EnableSriov: true, LinkType: "ETH"}
+	attrs := MlxNic{TotalVfs: 16, EnableSriov: true, LinkType: "ETH"}
+
+	changes := BuildPendingChanges("0000:ca:00.0", fwCurrent, attrs)
+
+	assert.Len(t, changes, 1)
+	assert.Equal(t, "0000:ca:00.0", changes[0].PciAddress)
+	assert.Equal(t, totalVfsConfigName, changes[0].Attribute)
+	assert.Equal(t, "8", changes[0].Current)
+	assert.Equal(t, "16", changes[0].Desired)
+	assert.True(t, changes[0].RequiresReboot)
+}
+
+func TestBuildPendingChanges_NeedFWResetDoesNotRequireReboot(t *testing.T) {
+	fwCurrent := MlxNic{TotalVfs: 8}
+	attrs := MlxNic{TotalVfs: 16, NeedFWReset: true}
+
+	changes := BuildPendingChanges("0000:ca:00.0", fwCurrent, attrs)
+
+	assert.Len(t, changes, 1)
+	assert.False(t, changes[0].RequiresReboot)
+}
+
 func makeStubCmd(t *testing.T) *stubCmd {
 	return &stubCmd{
 		baseFolder: "./testdata",
-		t: t,
+		t:          t,
 	}
 }
 
 type stubCmd struct {
 	baseFolder string
-	t *testing.T
+	t          *testing.T
 }
 
 func (s *stubCmd) RunCommand(cmd string, args ...string) (string, string, error) {
@@ -0,0 +1,62 @@
+// Package vendors defines the extension point vendor-specific SmartNIC/DPU logic plugs into, so
+// HostManager doesn't need to grow a new hard-coded `if vendorID == ...` branch for every NIC that
+// needs non-standard VF handling (e.g. Mellanox firmware-gated attributes, or a DPU whose VF PCI
+// addresses aren't discoverable via the stock dputils.GetVFList).
+package vendors
+
+import sriovnetworkv1 "github.com/k8snetworkplumbingwg/sriov-network-operator/api/v1"
+
+// VendorHandler is implemented by a package that knows how to configure VFs for a specific PCI
+// vendor/device. Handlers are looked up by PCI vendor ID via Lookup and are expected to be cheap,
+// side-effect-free to construct; all the real work happens in the hook methods below.
+type VendorHandler interface {
+	// PreConfig runs once per ConfigSriovInterfaces call, before any PF in ifaceStatuses is
+	// touched. kernelLockdownMode reports whether the node's kernel is running in lockdown mode.
+	// PreConfig returns an error to abort the whole sync (e.g. the Mellanox handler uses this to
+	// refuse configuring Mellanox PFs while the kernel is in lockdown mode).
+	PreConfig(ifaceStatuses []sriovnetworkv1.InterfaceExt, interfaces []sriovnetworkv1.Interface, kernelLockdownMode bool) error
+	// ConfigureVF applies any vendor-specific configuration a VF needs beyond what HostManager
+	// already does generically (admin MAC, MTU, driver bind). Most handlers can no-op here.
+	ConfigureVF(pfAddr, vfAddr string, group *sriovnetworkv1.VfGroup) error
+	// ResolveVFPCI returns the PCI address of VF number vfIndex under the PF at pfAddr. The
+	// default (dputils.GetVFList) assumes VFs are direct PCI children of the PF; DPU-style
+	// NICs that front VFs behind an internal switch need their own mapping here.
+	ResolveVFPCI(pfAddr string, vfIndex int) (string, error)
+	// PostConfig runs once per ConfigSriovInterfaces call after every PF has been configured.
+	PostConfig(ifaceStatuses []sriovnetworkv1.InterfaceExt, interfaces []sriovnetworkv1.Interface) error
+}
+
+var handlers = map[string]VendorHandler{}
+
+// Register associates a VendorHandler with a PCI vendor ID (e.g. "15b3" for Mellanox/NVIDIA).
+// Out-of-tree packages can call this from an init() to contribute a handler without HostManager
+// needing to know about them at compile time.
+func Register(vendorID string, handler VendorHandler) {
+	handlers[vendorID] = handler
+}
+
+// Lookup returns the VendorHandler registered for vendorID, or ok=false if none is registered
+// (the stock dputils-based VF handling is used in that case).
+func Lookup(vendorID string) (handler VendorHandler, ok bool) {
+	handler, ok = handlers[vendorID]
+	return handler, ok
+}
+
+// ForInterfaces returns the distinct set of VendorHandlers registered for the vendors present
+// among ifaceStatuses, in no particular order. ConfigSriovInterfaces uses this to run each
+// relevant handler's PreConfig/PostConfig hook exactly once per sync, regardless of how many PFs
+// of that vendor are present.
+func ForInterfaces(ifaceStatuses []sriovnetworkv1.InterfaceExt) []VendorHandler {
+	seen := map[string]bool{}
+	var result []VendorHandler
+	for _, s := range ifaceStatuses {
+		if seen[s.Vendor] {
+			continue
+		}
+		seen[s.Vendor] = true
+		if handler, ok := Lookup(s.Vendor); ok {
+			result = append(result, handler)
+		}
+	}
+	return result
+}